@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"net/mail"
+	"os"
+	"strings"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"golang.org/x/term"
+)
+
+// minPasswordLength matches the minimum enforced on sign up
+const minPasswordLength = 8
+
+// runCreateAdmin creates an admin account, so no default credential is needed
+// to bootstrap an instance
+func runCreateAdmin(args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the admin")
+	name := flags.String("name", "Admin", "name of the admin")
+	password := flags.String("password", "", "password of the admin, read from stdin when omitted")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errUsage
+	}
+	if _, err := mail.ParseAddress(*email); err != nil {
+		return fmt.Errorf("invalid email %q", *email)
+	}
+	if err := readPassword(password); err != nil {
+		return err
+	}
+
+	user, err := service.CreateAdminUser(*name, *email, *password)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("admin %s created with id %s\n", user.Email, user.ID)
+	return nil
+}
+
+// runResetPassword sets the password of an account, e.g. to recover the only admin
+func runResetPassword(args []string) error {
+	flags := flag.NewFlagSet("reset-password", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the account")
+	password := flags.String("password", "", "new password, read from stdin when omitted")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errUsage
+	}
+	if err := readPassword(password); err != nil {
+		return err
+	}
+
+	user, err := service.SetUserPassword(*email, *password)
+	if errors.Is(err, service.ErrUserNotFound) {
+		return fmt.Errorf("no account with email %s", *email)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("password of %s reset, every session was signed out\n", user.Email)
+	return nil
+}
+
+// readPassword reads the password from stdin unless it was given as a flag,
+// prompting without echo on a terminal. Passing it on stdin keeps it out of
+// the shell history and process list.
+func readPassword(password *string) error {
+	if *password == "" {
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			fmt.Fprint(os.Stderr, "Password: ")
+			line, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Fprintln(os.Stderr)
+			if err != nil {
+				return err
+			}
+			*password = string(line)
+		} else {
+			scanner := bufio.NewScanner(os.Stdin)
+			if scanner.Scan() {
+				*password = strings.TrimRight(scanner.Text(), "\r")
+			}
+			if err := scanner.Err(); err != nil {
+				return err
+			}
+		}
+	}
+	if len(*password) < minPasswordLength {
+		return fmt.Errorf("the password must have at least %d characters", minPasswordLength)
+	}
+	return nil
+}
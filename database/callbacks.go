@@ -0,0 +1,29 @@
+package database
+
+import "gorm.io/gorm"
+
+// registerAround registers before and after callbacks, named after the
+// plugin, around every kind of statement GORM runs. after is given the
+// operation of the statement: create, query, update, delete, row or raw.
+func registerAround(db *gorm.DB, plugin string, before func(*gorm.DB), after func(operation string) func(*gorm.DB)) error {
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("gorm:create").Register(plugin+":before_create", before),
+		callbacks.Create().After("gorm:create").Register(plugin+":after_create", after("create")),
+		callbacks.Query().Before("gorm:query").Register(plugin+":before_query", before),
+		callbacks.Query().After("gorm:query").Register(plugin+":after_query", after("query")),
+		callbacks.Update().Before("gorm:update").Register(plugin+":before_update", before),
+		callbacks.Update().After("gorm:update").Register(plugin+":after_update", after("update")),
+		callbacks.Delete().Before("gorm:delete").Register(plugin+":before_delete", before),
+		callbacks.Delete().After("gorm:delete").Register(plugin+":after_delete", after("delete")),
+		callbacks.Row().Before("gorm:row").Register(plugin+":before_row", before),
+		callbacks.Row().After("gorm:row").Register(plugin+":after_row", after("row")),
+		callbacks.Raw().Before("gorm:raw").Register(plugin+":before_raw", before),
+		callbacks.Raw().After("gorm:raw").Register(plugin+":after_raw", after("raw")),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,11 +2,14 @@ package database
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
 	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/metrics"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 
 	"gorm.io/gorm"
@@ -20,28 +23,119 @@ type Dbinstance struct {
 
 var Database Dbinstance
 
+// logLevels maps DB_LOG_LEVEL to the SQL logger level
+var logLevels = map[string]logger.LogLevel{
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
+}
+
 // Connect function
 func Connect() {
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta",
-		config.Config("DB_HOST"),
-		config.Config("DB_USER"),
-		config.Config("DB_PASSWORD"),
-		config.Config("DB_NAME"),
-		config.Config("DB_PORT"),
-	)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	})
+	db, err := Open(config.Get().Database)
 	if err != nil {
-		log.Fatal("Failed to connect to database. \n", err)
+		slog.Error("failed to connect to the database", "error", err)
 		os.Exit(2)
 	}
-	log.Println("Connected")
-	db.Logger = logger.Default.LogMode(logger.Info)
-	log.Println("running migrations")
-	db.AutoMigrate(&models.User{}, &models.Product{}, &models.Category{}, &models.Cart{}, &models.CartItem{}, &models.Order{}, &models.OrderItem{}, &models.Payment{})
+	slog.Info("connected to the database", "driver", config.Get().Database.Driver)
 	Database = Dbinstance{
 		Db: db,
 	}
 }
+
+// Open connects to the database of the configured driver and sets up the
+// connection pool, whose stats and statements are reported in the metrics
+// and traces
+func Open(settings config.DatabaseSettings) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch settings.Driver {
+	case "sqlite":
+		// Foreign keys are off by default in SQLite, and concurrent writers
+		// wait for each other instead of failing
+		separator := "?"
+		if strings.Contains(settings.SQLitePath, "?") {
+			separator = "&"
+		}
+		dialector = sqlite.Open(settings.SQLitePath + separator + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	default:
+		dialector = postgres.Open(fmt.Sprintf(
+			"host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s",
+			settings.Host,
+			settings.User,
+			settings.Password.Value(),
+			settings.Name,
+			settings.Port,
+			settings.SSLMode,
+			settings.TimeZone,
+		))
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: &sqlLogger{level: logLevels[settings.LogLevel]},
+	})
+	if err != nil {
+		return nil, err
+	}
+	system := "postgresql"
+	if settings.Driver == "sqlite" {
+		system = "sqlite"
+	}
+	if err := db.Use(metricsPlugin{}); err != nil {
+		return nil, err
+	}
+	if err := db.Use(tracingPlugin{system: system}); err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if settings.Driver == "sqlite" {
+		// SQLite has a single writer, and an in-memory database lives only as
+		// long as its one connection
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+		metrics.WatchPool(sqlDB, "sqlite")
+		return db, nil
+	}
+	sqlDB.SetMaxOpenConns(settings.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(settings.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(settings.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(settings.ConnMaxIdleTime)
+	metrics.WatchPool(sqlDB, settings.Name)
+	return db, nil
+}
+
+// PrepareSchema brings the schema up to date on startup. Outside production
+// pending migrations are applied, then AutoMigrate adds what models gained
+// since the last migration. In production the schema is only changed by the
+// migrate command, and startup fails while migrations are pending.
+func PrepareSchema() error {
+	db := Database.Db
+	if config.Get().Production() {
+		pending, err := PendingMigrations(db)
+		if err != nil {
+			return fmt.Errorf("could not read the migration status: %w", err)
+		}
+		if pending > 0 {
+			return fmt.Errorf("%d migrations are pending, run the migrate up command first", pending)
+		}
+		return nil
+	}
+
+	slog.Info("running migrations")
+	applied, err := MigrateUp(db, 0)
+	if err != nil {
+		return fmt.Errorf("could not apply migrations: %w", err)
+	}
+	for _, migration := range applied {
+		slog.Info("applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Product{}, &models.Category{}, &models.Cart{}, &models.CartItem{}, &models.Order{}, &models.OrderItem{}, &models.Payment{}, &models.RefreshToken{}, &models.RevokedToken{}, &models.PasswordResetToken{}, &models.RecoveryCode{}, &models.LoginAttempt{}, &models.SecurityEvent{}, &models.APIKey{}, &models.UserIdentity{}, &models.Session{}, &models.ImpersonationLog{}); err != nil {
+		return fmt.Errorf("could not auto-migrate models: %w", err)
+	}
+	return nil
+}
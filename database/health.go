@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnreachable       = errors.New("database unreachable")
+	ErrMigrationsPending = errors.New("migrations pending")
+)
+
+// Ready checks that the database answers and has every migration applied,
+// returning ErrUnreachable or ErrMigrationsPending wrapping the cause
+func Ready(ctx context.Context) error {
+	db := Database.Db.WithContext(ctx)
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+	pending, err := PendingMigrations(db)
+	if err != nil {
+		return fmt.Errorf("%w: could not read the migration status: %v", ErrUnreachable, err)
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w: %d to apply", ErrMigrationsPending, pending)
+	}
+	return nil
+}
+
+// Close closes the connection pool, once nothing uses the database anymore
+func Close() error {
+	if Database.Db == nil {
+		return nil
+	}
+	sqlDB, err := Database.Db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
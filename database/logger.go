@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// slowQueryThreshold is the duration from which a statement is logged as slow
+const slowQueryThreshold = 200 * time.Millisecond
+
+// sqlLogger writes the GORM logs with slog, so statements run for a request
+// carry its request ID. Statements keep their placeholders, bound values such
+// as password hashes are never logged.
+type sqlLogger struct {
+	level logger.LogLevel
+}
+
+func (l *sqlLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &sqlLogger{level: level}
+}
+
+func (l *sqlLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *sqlLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *sqlLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *sqlLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error:
+		sql, rows := fc()
+		slog.ErrorContext(ctx, "sql statement failed", "sql", sql, "rows", rows, "duration", elapsed, "error", err)
+	case elapsed > slowQueryThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		slog.WarnContext(ctx, "slow sql statement", "sql", sql, "rows", rows, "duration", elapsed)
+	case l.level >= logger.Info && slog.Default().Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		slog.DebugContext(ctx, "sql statement", "sql", sql, "rows", rows, "duration", elapsed)
+	}
+}
+
+// ParamsFilter keeps the bound values out of the statements passed to Trace
+func (l *sqlLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
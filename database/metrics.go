@@ -0,0 +1,35 @@
+package database
+
+import (
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/metrics"
+	"gorm.io/gorm"
+)
+
+// queryStartKey holds the start time of a statement in its GORM instance
+const queryStartKey = "metrics:query_start"
+
+// metricsPlugin times the statements run through GORM for the
+// db_query_duration_seconds histogram
+type metricsPlugin struct{}
+
+func (metricsPlugin) Name() string { return "metrics" }
+
+func (metricsPlugin) Initialize(db *gorm.DB) error {
+	return registerAround(db, "metrics", startQuery, observeQuery)
+}
+
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func observeQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		start, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		metrics.ObserveQuery(operation, db.Statement.Table, time.Since(start.(time.Time)))
+	}
+}
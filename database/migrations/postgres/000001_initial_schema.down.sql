@@ -0,0 +1,9 @@
+-- The uuid-ossp extension is left installed, other schemas may use it.
+DROP TABLE IF EXISTS "payments";
+DROP TABLE IF EXISTS "order_items";
+DROP TABLE IF EXISTS "orders";
+DROP TABLE IF EXISTS "cart_items";
+DROP TABLE IF EXISTS "carts";
+DROP TABLE IF EXISTS "products";
+DROP TABLE IF EXISTS "categories";
+DROP TABLE IF EXISTS "users";
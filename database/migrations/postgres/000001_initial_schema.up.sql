@@ -0,0 +1,102 @@
+-- Schema created by AutoMigrate before versioned migrations. IF NOT EXISTS lets
+-- databases created that way adopt this migration as is, so it must not differ
+-- from that schema: later changes go in the following migrations.
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "name" varchar(100) NOT NULL,
+    "email" varchar(100) NOT NULL,
+    "password" varchar(100) NOT NULL,
+    "role" text DEFAULT 'customer',
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+
+CREATE TABLE IF NOT EXISTS "categories" (
+    "id" uuid,
+    "name" varchar(100) NOT NULL,
+    "description" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "products" (
+    "id" uuid,
+    "name" varchar(100) NOT NULL,
+    "description" text,
+    "price" decimal(10,2) NOT NULL,
+    "stock" bigint NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "category_refer" uuid,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_products_category" FOREIGN KEY ("category_refer") REFERENCES "categories"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_products_category_refer" ON "products" ("category_refer");
+
+CREATE TABLE IF NOT EXISTS "carts" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "user_refer" uuid,
+    "total_amount" decimal(10,2) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_carts_user" FOREIGN KEY ("user_refer") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_carts_user_refer" ON "carts" ("user_refer");
+
+CREATE TABLE IF NOT EXISTS "cart_items" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "cart_refer" uuid,
+    "product_refer" uuid,
+    "quantity" bigint NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_cart_items_cart" FOREIGN KEY ("cart_refer") REFERENCES "carts"("id"),
+    CONSTRAINT "fk_cart_items_product" FOREIGN KEY ("product_refer") REFERENCES "products"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_cart_items_product_refer" ON "cart_items" ("product_refer");
+CREATE INDEX IF NOT EXISTS "idx_cart_items_cart_refer" ON "cart_items" ("cart_refer");
+
+CREATE TABLE IF NOT EXISTS "orders" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "user_refer" uuid,
+    "status" text DEFAULT 'pending',
+    "total_amount" decimal(10,2) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_orders_user" FOREIGN KEY ("user_refer") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_orders_user_refer" ON "orders" ("user_refer");
+
+CREATE TABLE IF NOT EXISTS "order_items" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "order_refer" uuid,
+    "price_at_purchase" decimal(10,2) NOT NULL,
+    "quantity" bigint NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_order_items_order" FOREIGN KEY ("order_refer") REFERENCES "orders"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_order_items_order_refer" ON "order_items" ("order_refer");
+
+CREATE TABLE IF NOT EXISTS "payments" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "order_refer" uuid,
+    "status" text DEFAULT 'unpaid',
+    "amount" decimal(10,2) NOT NULL,
+    "method" text NOT NULL,
+    "otp" text NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_payments_order" FOREIGN KEY ("order_refer") REFERENCES "orders"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_payments_order_refer" ON "payments" ("order_refer");
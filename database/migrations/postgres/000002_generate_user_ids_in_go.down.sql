@@ -0,0 +1 @@
+ALTER TABLE "users" ALTER COLUMN "id" SET DEFAULT uuid_generate_v4();
@@ -0,0 +1,3 @@
+-- User IDs are generated by the application like every other ID, so the
+-- schema no longer depends on the uuid-ossp extension.
+ALTER TABLE "users" ALTER COLUMN "id" DROP DEFAULT;
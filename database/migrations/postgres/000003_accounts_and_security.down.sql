@@ -0,0 +1,20 @@
+DROP TABLE IF EXISTS "impersonation_logs";
+DROP TABLE IF EXISTS "sessions";
+DROP TABLE IF EXISTS "user_identities";
+DROP TABLE IF EXISTS "api_keys";
+DROP TABLE IF EXISTS "security_events";
+DROP TABLE IF EXISTS "login_attempts";
+DROP TABLE IF EXISTS "recovery_codes";
+DROP TABLE IF EXISTS "password_reset_tokens";
+DROP TABLE IF EXISTS "revoked_tokens";
+DROP TABLE IF EXISTS "refresh_tokens";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "token_version";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "verified_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "totp_secret";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "totp_last_used_step";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "two_factor_enabled_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "suspended_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "suspension_reason";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "password_reset_required";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "anonymized_at";
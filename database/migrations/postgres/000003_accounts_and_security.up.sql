@@ -0,0 +1,152 @@
+-- Columns and tables of accounts, sessions and security added after the
+-- initial schema. IF NOT EXISTS keeps it applicable to databases whose
+-- development server already added them with AutoMigrate.
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "token_version" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "verified_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_secret" varchar(64);
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_last_used_step" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "two_factor_enabled_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "suspended_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "suspension_reason" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "password_reset_required" boolean NOT NULL DEFAULT false;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "anonymized_at" timestamptz;
+
+CREATE TABLE IF NOT EXISTS "refresh_tokens" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "user_refer" uuid NOT NULL,
+    "session_refer" uuid,
+    "token_hash" varchar(64) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "revoked_at" timestamptz,
+    "replaced_by" uuid,
+    "mfa" boolean NOT NULL DEFAULT false,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_refresh_tokens_user" FOREIGN KEY ("user_refer") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_refresh_tokens_token_hash" ON "refresh_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_session_refer" ON "refresh_tokens" ("session_refer");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_user_refer" ON "refresh_tokens" ("user_refer");
+
+CREATE TABLE IF NOT EXISTS "revoked_tokens" (
+    "token_id" uuid,
+    "created_at" timestamptz,
+    "user_refer" uuid,
+    "expires_at" timestamptz NOT NULL,
+    PRIMARY KEY ("token_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_revoked_tokens_expires_at" ON "revoked_tokens" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_revoked_tokens_user_refer" ON "revoked_tokens" ("user_refer");
+
+CREATE TABLE IF NOT EXISTS "password_reset_tokens" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "user_refer" uuid NOT NULL,
+    "token_hash" varchar(64) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_password_reset_tokens_user" FOREIGN KEY ("user_refer") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_password_reset_tokens_token_hash" ON "password_reset_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_password_reset_tokens_user_refer" ON "password_reset_tokens" ("user_refer");
+
+CREATE TABLE IF NOT EXISTS "recovery_codes" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "user_refer" uuid NOT NULL,
+    "code_hash" varchar(64) NOT NULL,
+    "used_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_recovery_codes_user" FOREIGN KEY ("user_refer") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_user_refer" ON "recovery_codes" ("user_refer");
+
+CREATE TABLE IF NOT EXISTS "login_attempts" (
+    "attempt_key" varchar(255),
+    "failures" bigint NOT NULL DEFAULT 0,
+    "last_failure_at" timestamptz NOT NULL,
+    "locked_until" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("attempt_key")
+);
+
+CREATE TABLE IF NOT EXISTS "security_events" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "type" varchar(50) NOT NULL,
+    "email" varchar(100),
+    "ip" varchar(64),
+    "user_refer" uuid,
+    "detail" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_security_events_user_refer" ON "security_events" ("user_refer");
+CREATE INDEX IF NOT EXISTS "idx_security_events_email" ON "security_events" ("email");
+CREATE INDEX IF NOT EXISTS "idx_security_events_type" ON "security_events" ("type");
+CREATE INDEX IF NOT EXISTS "idx_security_events_created_at" ON "security_events" ("created_at");
+
+CREATE TABLE IF NOT EXISTS "api_keys" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "name" varchar(100) NOT NULL,
+    "prefix" varchar(16) NOT NULL,
+    "key_hash" varchar(64) NOT NULL,
+    "scopes" text NOT NULL,
+    "created_by_refer" uuid,
+    "expires_at" timestamptz,
+    "revoked_at" timestamptz,
+    "last_used_at" timestamptz,
+    "last_used_ip" varchar(64),
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_api_keys_created_by_refer" ON "api_keys" ("created_by_refer");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_key_hash" ON "api_keys" ("key_hash");
+
+CREATE TABLE IF NOT EXISTS "user_identities" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "user_refer" uuid NOT NULL,
+    "provider" varchar(50) NOT NULL,
+    "subject" varchar(255) NOT NULL,
+    "email" varchar(100),
+    "last_login_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_identities_user" FOREIGN KEY ("user_refer") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_identities_provider_subject" ON "user_identities" ("provider","subject");
+CREATE INDEX IF NOT EXISTS "idx_user_identities_user_refer" ON "user_identities" ("user_refer");
+
+CREATE TABLE IF NOT EXISTS "sessions" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "user_refer" uuid NOT NULL,
+    "user_agent" varchar(255),
+    "ip" varchar(64),
+    "last_active_at" timestamptz NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "revoked_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_sessions_user" FOREIGN KEY ("user_refer") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_sessions_user_refer" ON "sessions" ("user_refer");
+
+CREATE TABLE IF NOT EXISTS "impersonation_logs" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "actor_refer" uuid NOT NULL,
+    "subject_refer" uuid NOT NULL,
+    "token_id" varchar(64),
+    "method" varchar(10),
+    "path" varchar(255),
+    "status" bigint,
+    "ip" varchar(64),
+    "blocked" boolean NOT NULL DEFAULT false,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_impersonation_logs_token_id" ON "impersonation_logs" ("token_id");
+CREATE INDEX IF NOT EXISTS "idx_impersonation_logs_subject_refer" ON "impersonation_logs" ("subject_refer");
+CREATE INDEX IF NOT EXISTS "idx_impersonation_logs_actor_refer" ON "impersonation_logs" ("actor_refer");
+CREATE INDEX IF NOT EXISTS "idx_impersonation_logs_created_at" ON "impersonation_logs" ("created_at");
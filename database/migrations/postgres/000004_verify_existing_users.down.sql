@@ -0,0 +1,2 @@
+-- Backfilled verification dates cannot be told apart from real ones, they are kept.
+SELECT 1;
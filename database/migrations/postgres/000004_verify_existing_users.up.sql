@@ -0,0 +1,3 @@
+-- Accounts created before email verification existed never received a link,
+-- they are taken as verified since they signed up so checkout keeps working.
+UPDATE "users" SET "verified_at" = COALESCE("created_at", NOW()) WHERE "verified_at" IS NULL;
@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS "impersonation_logs";
+DROP TABLE IF EXISTS "sessions";
+DROP TABLE IF EXISTS "user_identities";
+DROP TABLE IF EXISTS "api_keys";
+DROP TABLE IF EXISTS "security_events";
+DROP TABLE IF EXISTS "login_attempts";
+DROP TABLE IF EXISTS "recovery_codes";
+DROP TABLE IF EXISTS "password_reset_tokens";
+DROP TABLE IF EXISTS "revoked_tokens";
+DROP TABLE IF EXISTS "refresh_tokens";
+DROP TABLE IF EXISTS "payments";
+DROP TABLE IF EXISTS "order_items";
+DROP TABLE IF EXISTS "orders";
+DROP TABLE IF EXISTS "cart_items";
+DROP TABLE IF EXISTS "carts";
+DROP TABLE IF EXISTS "products";
+DROP TABLE IF EXISTS "categories";
+DROP TABLE IF EXISTS "users";
@@ -0,0 +1,247 @@
+-- Schema of the models for the SQLite driver, used for local development and tests.
+CREATE TABLE "users" (
+    "id" uuid,
+    "name" varchar(100) NOT NULL,
+    "email" varchar(100) NOT NULL,
+    "password" varchar(100) NOT NULL,
+    "role" text DEFAULT 'customer',
+    "created_at" datetime,
+    "updated_at" datetime,
+    "token_version" integer NOT NULL DEFAULT 0,
+    "verified_at" datetime,
+    "totp_secret" varchar(64),
+    "totp_last_used_step" integer NOT NULL DEFAULT 0,
+    "two_factor_enabled_at" datetime,
+    "suspended_at" datetime,
+    "suspension_reason" text,
+    "password_reset_required" numeric NOT NULL DEFAULT false,
+    "anonymized_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX "idx_users_email" ON "users" ("email");
+
+CREATE TABLE "categories" (
+    "id" uuid,
+    "name" varchar(100) NOT NULL,
+    "description" text,
+    "created_at" datetime,
+    "updated_at" datetime,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE "products" (
+    "id" uuid,
+    "name" varchar(100) NOT NULL,
+    "description" text,
+    "price" decimal(10,2) NOT NULL,
+    "stock" integer NOT NULL,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "category_refer" uuid,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_products_category" FOREIGN KEY ("category_refer") REFERENCES "categories" ("id")
+);
+CREATE INDEX "idx_products_category_refer" ON "products" ("category_refer");
+
+CREATE TABLE "carts" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "user_refer" uuid,
+    "total_amount" decimal(10,2) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_carts_user" FOREIGN KEY ("user_refer") REFERENCES "users" ("id")
+);
+CREATE INDEX "idx_carts_user_refer" ON "carts" ("user_refer");
+
+CREATE TABLE "cart_items" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "cart_refer" uuid,
+    "product_refer" uuid,
+    "quantity" integer NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_cart_items_cart" FOREIGN KEY ("cart_refer") REFERENCES "carts" ("id"),
+    CONSTRAINT "fk_cart_items_product" FOREIGN KEY ("product_refer") REFERENCES "products" ("id")
+);
+CREATE INDEX "idx_cart_items_cart_refer" ON "cart_items" ("cart_refer");
+CREATE INDEX "idx_cart_items_product_refer" ON "cart_items" ("product_refer");
+
+CREATE TABLE "orders" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "user_refer" uuid,
+    "status" text DEFAULT 'pending',
+    "total_amount" decimal(10,2) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_orders_user" FOREIGN KEY ("user_refer") REFERENCES "users" ("id")
+);
+CREATE INDEX "idx_orders_user_refer" ON "orders" ("user_refer");
+
+CREATE TABLE "order_items" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "order_refer" uuid,
+    "price_at_purchase" decimal(10,2) NOT NULL,
+    "quantity" integer NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_order_items_order" FOREIGN KEY ("order_refer") REFERENCES "orders" ("id")
+);
+CREATE INDEX "idx_order_items_order_refer" ON "order_items" ("order_refer");
+
+CREATE TABLE "payments" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "order_refer" uuid,
+    "status" text DEFAULT 'unpaid',
+    "amount" decimal(10,2) NOT NULL,
+    "method" text NOT NULL,
+    "otp" text NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_payments_order" FOREIGN KEY ("order_refer") REFERENCES "orders" ("id")
+);
+CREATE INDEX "idx_payments_order_refer" ON "payments" ("order_refer");
+
+CREATE TABLE "refresh_tokens" (
+    "id" uuid,
+    "created_at" datetime,
+    "user_refer" uuid NOT NULL,
+    "session_refer" uuid,
+    "token_hash" varchar(64) NOT NULL,
+    "expires_at" datetime NOT NULL,
+    "revoked_at" datetime,
+    "replaced_by" uuid,
+    "mfa" numeric NOT NULL DEFAULT false,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_refresh_tokens_user" FOREIGN KEY ("user_refer") REFERENCES "users" ("id")
+);
+CREATE UNIQUE INDEX "idx_refresh_tokens_token_hash" ON "refresh_tokens" ("token_hash");
+CREATE INDEX "idx_refresh_tokens_session_refer" ON "refresh_tokens" ("session_refer");
+CREATE INDEX "idx_refresh_tokens_user_refer" ON "refresh_tokens" ("user_refer");
+
+CREATE TABLE "revoked_tokens" (
+    "token_id" uuid,
+    "created_at" datetime,
+    "user_refer" uuid,
+    "expires_at" datetime NOT NULL,
+    PRIMARY KEY ("token_id")
+);
+CREATE INDEX "idx_revoked_tokens_user_refer" ON "revoked_tokens" ("user_refer");
+CREATE INDEX "idx_revoked_tokens_expires_at" ON "revoked_tokens" ("expires_at");
+
+CREATE TABLE "password_reset_tokens" (
+    "id" uuid,
+    "created_at" datetime,
+    "user_refer" uuid NOT NULL,
+    "token_hash" varchar(64) NOT NULL,
+    "expires_at" datetime NOT NULL,
+    "used_at" datetime,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_password_reset_tokens_user" FOREIGN KEY ("user_refer") REFERENCES "users" ("id")
+);
+CREATE UNIQUE INDEX "idx_password_reset_tokens_token_hash" ON "password_reset_tokens" ("token_hash");
+CREATE INDEX "idx_password_reset_tokens_user_refer" ON "password_reset_tokens" ("user_refer");
+
+CREATE TABLE "recovery_codes" (
+    "id" uuid,
+    "created_at" datetime,
+    "user_refer" uuid NOT NULL,
+    "code_hash" varchar(64) NOT NULL,
+    "used_at" datetime,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_recovery_codes_user" FOREIGN KEY ("user_refer") REFERENCES "users" ("id")
+);
+CREATE INDEX "idx_recovery_codes_user_refer" ON "recovery_codes" ("user_refer");
+
+CREATE TABLE "login_attempts" (
+    "attempt_key" varchar(255),
+    "failures" integer NOT NULL DEFAULT 0,
+    "last_failure_at" datetime NOT NULL,
+    "locked_until" datetime,
+    "updated_at" datetime,
+    PRIMARY KEY ("attempt_key")
+);
+
+CREATE TABLE "security_events" (
+    "id" uuid,
+    "created_at" datetime,
+    "type" varchar(50) NOT NULL,
+    "email" varchar(100),
+    "ip" varchar(64),
+    "user_refer" uuid,
+    "detail" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_security_events_created_at" ON "security_events" ("created_at");
+CREATE INDEX "idx_security_events_user_refer" ON "security_events" ("user_refer");
+CREATE INDEX "idx_security_events_email" ON "security_events" ("email");
+CREATE INDEX "idx_security_events_type" ON "security_events" ("type");
+
+CREATE TABLE "api_keys" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "name" varchar(100) NOT NULL,
+    "prefix" varchar(16) NOT NULL,
+    "key_hash" varchar(64) NOT NULL,
+    "scopes" text NOT NULL,
+    "created_by_refer" uuid,
+    "expires_at" datetime,
+    "revoked_at" datetime,
+    "last_used_at" datetime,
+    "last_used_ip" varchar(64),
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_api_keys_created_by_refer" ON "api_keys" ("created_by_refer");
+CREATE UNIQUE INDEX "idx_api_keys_key_hash" ON "api_keys" ("key_hash");
+
+CREATE TABLE "user_identities" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "user_refer" uuid NOT NULL,
+    "provider" varchar(50) NOT NULL,
+    "subject" varchar(255) NOT NULL,
+    "email" varchar(100),
+    "last_login_at" datetime,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_identities_user" FOREIGN KEY ("user_refer") REFERENCES "users" ("id")
+);
+CREATE UNIQUE INDEX "idx_user_identities_provider_subject" ON "user_identities" ("provider","subject");
+CREATE INDEX "idx_user_identities_user_refer" ON "user_identities" ("user_refer");
+
+CREATE TABLE "sessions" (
+    "id" uuid,
+    "created_at" datetime,
+    "user_refer" uuid NOT NULL,
+    "user_agent" varchar(255),
+    "ip" varchar(64),
+    "last_active_at" datetime NOT NULL,
+    "expires_at" datetime NOT NULL,
+    "revoked_at" datetime,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_sessions_user" FOREIGN KEY ("user_refer") REFERENCES "users" ("id")
+);
+CREATE INDEX "idx_sessions_user_refer" ON "sessions" ("user_refer");
+
+CREATE TABLE "impersonation_logs" (
+    "id" uuid,
+    "created_at" datetime,
+    "actor_refer" uuid NOT NULL,
+    "subject_refer" uuid NOT NULL,
+    "token_id" varchar(64),
+    "method" varchar(10),
+    "path" varchar(255),
+    "status" integer,
+    "ip" varchar(64),
+    "blocked" numeric NOT NULL DEFAULT false,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_impersonation_logs_created_at" ON "impersonation_logs" ("created_at");
+CREATE INDEX "idx_impersonation_logs_token_id" ON "impersonation_logs" ("token_id");
+CREATE INDEX "idx_impersonation_logs_subject_refer" ON "impersonation_logs" ("subject_refer");
+CREATE INDEX "idx_impersonation_logs_actor_refer" ON "impersonation_logs" ("actor_refer");
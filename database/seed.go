@@ -1,41 +1,387 @@
 package database
 
 import (
-	"log"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/passhash"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// SeedData is the content of a fixture file. Records are matched to existing
+// ones by their natural key (category slug, product slug, user email) and
+// updated, so seeding the same file again changes nothing.
 type SeedData struct {
-	Categories []models.Category `json:"categories"`
-	Products   []models.Product  `json:"products"`
+	Categories []SeedCategory `json:"categories" yaml:"categories"`
+	Products   []SeedProduct  `json:"products" yaml:"products"`
+	Users      []SeedUser     `json:"users" yaml:"users"`
+	Orders     []SeedOrder    `json:"orders" yaml:"orders"`
 }
 
-func InitializeAdminUser() {
-	var admin models.User
-	result := Database.Db.Where("email = ?", "admin@mystore.com").First(&admin)
+type SeedCategory struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+}
+
+type SeedProduct struct {
+	Name        string  `json:"name" yaml:"name"`
+	Description string  `json:"description" yaml:"description"`
+	Price       float64 `json:"price" yaml:"price"`
+	Stock       int     `json:"stock" yaml:"stock"`
+	// Category is the name or slug of a category of the file or of the database
+	Category string `json:"category" yaml:"category"`
+}
+
+type SeedUser struct {
+	Name  string `json:"name" yaml:"name"`
+	Email string `json:"email" yaml:"email"`
+	// Password is only set when the user is created
+	Password string `json:"password" yaml:"password"`
+	Role     string `json:"role" yaml:"role"`
+	Verified bool   `json:"verified" yaml:"verified"`
+}
+
+// SeedOrder is a demo order of a seeded user. The orders of a user are only
+// created while the user has none, as orders have no natural key.
+type SeedOrder struct {
+	User   string          `json:"user" yaml:"user"`
+	Status string          `json:"status" yaml:"status"`
+	Items  []SeedOrderItem `json:"items" yaml:"items"`
+}
+
+type SeedOrderItem struct {
+	// Product is the name or slug of a product of the file
+	Product  string `json:"product" yaml:"product"`
+	Quantity int    `json:"quantity" yaml:"quantity"`
+}
+
+// SeedResult counts the records a seed created and updated
+type SeedResult struct {
+	Created int
+	Updated int
+}
+
+// LoadSeedData reads a fixture file, YAML when its extension is .yaml or .yml
+// and JSON otherwise
+func LoadSeedData(path string) (*SeedData, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var data SeedData
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &data)
+	default:
+		err = json.Unmarshal(content, &data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &data, nil
+}
+
+// SeedFile seeds the fixtures of a file in a single transaction
+func SeedFile(path string) (SeedResult, error) {
+	data, err := LoadSeedData(path)
+	if err != nil {
+		return SeedResult{}, err
+	}
+	var result SeedResult
+	err = Database.Db.Transaction(func(tx *gorm.DB) error {
+		return (&seeder{tx: tx, result: &result}).seed(data)
+	})
+	return result, err
+}
+
+// Slug returns the lower-case, hyphen separated form of a name, e.g.
+// "Home & Kitchen" becomes "home-kitchen"
+func Slug(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	return b.String()
+}
+
+type seeder struct {
+	tx     *gorm.DB
+	result *SeedResult
+	// categories and products by slug, users by email
+	categories map[string]*models.Category
+	products   map[string]*models.Product
+	users      map[string]*models.User
+	// storedProducts are the products of the database by slug
+	storedProducts map[string]*models.Product
+}
+
+func (s *seeder) seed(data *SeedData) error {
+	if err := s.loadCategories(); err != nil {
+		return err
+	}
+	for _, category := range data.Categories {
+		if err := s.seedCategory(category); err != nil {
+			return err
+		}
+	}
+	if err := s.loadProducts(); err != nil {
+		return err
+	}
+	s.products = map[string]*models.Product{}
+	for _, product := range data.Products {
+		if err := s.seedProduct(product); err != nil {
+			return err
+		}
+	}
+	s.users = map[string]*models.User{}
+	for _, user := range data.Users {
+		if err := s.seedUser(user); err != nil {
+			return err
+		}
+	}
+
+	// Orders are grouped per user, as they are only created for users without any
+	ordersByUser := map[string][]SeedOrder{}
+	var emails []string
+	for _, order := range data.Orders {
+		email := strings.ToLower(order.User)
+		if _, ok := ordersByUser[email]; !ok {
+			emails = append(emails, email)
+		}
+		ordersByUser[email] = append(ordersByUser[email], order)
+	}
+	for _, email := range emails {
+		if err := s.seedOrders(email, ordersByUser[email]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadCategories indexes the existing categories by the slug of their name
+func (s *seeder) loadCategories() error {
+	var categories []models.Category
+	if err := s.tx.Find(&categories).Error; err != nil {
+		return err
+	}
+	s.categories = make(map[string]*models.Category, len(categories))
+	for i := range categories {
+		s.categories[Slug(categories[i].Name)] = &categories[i]
+	}
+	return nil
+}
+
+// loadProducts indexes the existing products by the slug of their name
+func (s *seeder) loadProducts() error {
+	var products []models.Product
+	if err := s.tx.Find(&products).Error; err != nil {
+		return err
+	}
+	s.storedProducts = make(map[string]*models.Product, len(products))
+	for i := range products {
+		s.storedProducts[Slug(products[i].Name)] = &products[i]
+	}
+	return nil
+}
+
+func (s *seeder) seedCategory(fixture SeedCategory) error {
+	slug := Slug(fixture.Name)
+	if slug == "" {
+		return fmt.Errorf("category %q: a name is required", fixture.Name)
+	}
+
+	category, ok := s.categories[slug]
+	if !ok {
+		category = &models.Category{Name: fixture.Name, Description: fixture.Description}
+		if err := s.tx.Create(category).Error; err != nil {
+			return err
+		}
+		s.categories[slug] = category
+		s.result.Created++
+		return nil
+	}
+	if category.Name == fixture.Name && category.Description == fixture.Description {
+		return nil
+	}
+	category.Name, category.Description = fixture.Name, fixture.Description
+	if err := s.tx.Save(category).Error; err != nil {
+		return err
+	}
+	s.result.Updated++
+	return nil
+}
+
+func (s *seeder) seedProduct(fixture SeedProduct) error {
+	slug := Slug(fixture.Name)
+	if slug == "" {
+		return fmt.Errorf("product %q: a name is required", fixture.Name)
+	}
+	if fixture.Price < 0 || fixture.Stock < 0 {
+		return fmt.Errorf("product %q: price and stock cannot be negative", fixture.Name)
+	}
+	category, ok := s.categories[Slug(fixture.Category)]
+	if !ok {
+		return fmt.Errorf("product %q: unknown category %q", fixture.Name, fixture.Category)
+	}
+
+	product, ok := s.storedProducts[slug]
+	if !ok {
+		product = &models.Product{}
+	}
+	changed := product.Name != fixture.Name || product.Description != fixture.Description ||
+		product.Price != fixture.Price || product.Stock != fixture.Stock || product.CategoryRefer != category.ID
+	product.Name = fixture.Name
+	product.Description = fixture.Description
+	product.Price = fixture.Price
+	product.Stock = fixture.Stock
+	product.CategoryRefer = category.ID
+
+	switch {
+	case !ok:
+		if err := s.tx.Omit(clause.Associations).Create(product).Error; err != nil {
+			return err
+		}
+		s.storedProducts[slug] = product
+		s.result.Created++
+	case changed:
+		if err := s.tx.Omit(clause.Associations).Save(product).Error; err != nil {
+			return err
+		}
+		s.result.Updated++
+	}
+	s.products[slug] = product
+	return nil
+}
+
+func (s *seeder) seedUser(fixture SeedUser) error {
+	email := strings.ToLower(fixture.Email)
+	if email == "" {
+		return fmt.Errorf("user %q: an email is required", fixture.Name)
+	}
+	role := fixture.Role
+	if role == "" {
+		role = models.Customer
+	}
+	if !models.IsValidRole(role) {
+		return fmt.Errorf("user %s: unknown role %q", email, role)
+	}
+
+	var user models.User
+	if err := s.tx.Where("email = ?", email).Limit(1).Find(&user).Error; err != nil {
+		return err
+	}
 
-	if result.Error != nil && result.Error.Error() == "record not found" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte("mystore_admin"), 14)
+	if user.CreatedAt.IsZero() {
+		if len(fixture.Password) < 8 {
+			return fmt.Errorf("user %s: a password of at least 8 characters is required", email)
+		}
+		passwordHash, err := passhash.Hash(fixture.Password)
 		if err != nil {
-			log.Fatalf("Failed to hash password: %v", err)
+			return err
+		}
+		user = models.User{Name: fixture.Name, Email: email, Password: passwordHash, Role: role}
+		if fixture.Verified {
+			now := time.Now()
+			user.VerifiedAt = &now
 		}
+		if err := s.tx.Create(&user).Error; err != nil {
+			return err
+		}
+		s.result.Created++
+		s.users[email] = &user
+		return nil
+	}
 
-		admin := models.User{
+	updates := map[string]interface{}{}
+	if user.Name != fixture.Name {
+		updates["name"] = fixture.Name
+	}
+	if user.Role != role {
+		updates["role"] = role
+		// Tokens carry the role, sign the user out so the new one applies
+		updates["token_version"] = gorm.Expr("token_version + 1")
+	}
+	if fixture.Verified && user.VerifiedAt == nil {
+		updates["verified_at"] = time.Now()
+	}
+	if len(updates) > 0 {
+		if err := s.tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+		s.result.Updated++
+	}
+	s.users[email] = &user
+	return nil
+}
+
+func (s *seeder) seedOrders(email string, fixtures []SeedOrder) error {
+	user, ok := s.users[email]
+	if !ok {
+		return fmt.Errorf("order of %s: the user must be listed in users", email)
+	}
+	var existing int64
+	if err := s.tx.Model(&models.Order{}).Where("user_refer = ?", user.ID).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return nil
+	}
 
-			Name:     "Admin",
-			Email:    "admin@mystore.com",
-			Password: string(hashedPassword),
-			Role:     models.Admin,
+	for _, fixture := range fixtures {
+		status := fixture.Status
+		if status == "" {
+			status = string(models.Pending)
+		}
+		switch models.OrderStatus(status) {
+		case models.Pending, models.PaidOrder, models.Completed, models.Canceled:
+		default:
+			return fmt.Errorf("order of %s: unknown status %q", email, status)
+		}
+		if len(fixture.Items) == 0 {
+			return fmt.Errorf("order of %s: at least one item is required", email)
 		}
 
-		if err := Database.Db.Create(&admin).Error; err != nil {
-			log.Fatalf("Failed to create admin user: %v", err)
+		order := models.Order{UserRefer: user.ID, Status: status}
+		var items []models.OrderItem
+		for _, item := range fixture.Items {
+			product, ok := s.products[Slug(item.Product)]
+			if !ok {
+				return fmt.Errorf("order of %s: product %q must be listed in products", email, item.Product)
+			}
+			if item.Quantity < 1 {
+				return fmt.Errorf("order of %s: quantity of %q must be positive", email, item.Product)
+			}
+			items = append(items, models.OrderItem{PriceAtPurchase: product.Price, Quantity: item.Quantity})
+			order.TotalAmount += product.Price * float64(item.Quantity)
 		}
 
-		log.Println("Admin user created successfully")
-	} else {
-		log.Println("Admin user already exists")
+		if err := s.tx.Omit(clause.Associations).Create(&order).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].OrderRefer = order.ID
+		}
+		if err := s.tx.Omit(clause.Associations).Create(&items).Error; err != nil {
+			return err
+		}
+		s.result.Created += 1 + len(items)
 	}
+	return nil
 }
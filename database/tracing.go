@@ -0,0 +1,66 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// querySpanKey holds the span of a statement in its GORM instance
+const querySpanKey = "tracing:query_span"
+
+// tracingPlugin adds a span for each statement run with the context of a
+// traced operation. Statements outside one, such as migrations, are left out
+// rather than each starting a trace of its own.
+type tracingPlugin struct {
+	system string
+}
+
+func (tracingPlugin) Name() string { return "tracing" }
+
+func (p tracingPlugin) Initialize(db *gorm.DB) error {
+	return registerAround(db, "tracing", p.startSpan, p.endSpan)
+}
+
+func (p tracingPlugin) startSpan(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	_, span := tracing.Start(ctx, "sql", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemKey.String(p.system)))
+	db.InstanceSet(querySpanKey, span)
+}
+
+func (p tracingPlugin) endSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(querySpanKey)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		table := db.Statement.Table
+		name := operation
+		if table != "" {
+			name += " " + table
+		}
+		span.SetName(name)
+		// The statement keeps its placeholders, bound values are never recorded
+		span.SetAttributes(
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(db.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", db.RowsAffected),
+		)
+		if table != "" {
+			span.SetAttributes(semconv.DBCollectionName(table))
+		}
+		if !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			tracing.Fail(span, db.Error)
+		}
+		span.End()
+	}
+}
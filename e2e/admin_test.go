@@ -0,0 +1,199 @@
+package e2e_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/apptest"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/google/uuid"
+)
+
+func TestAdminManagesCatalog(t *testing.T) {
+	s := newShop(t, 5)
+	productPath := "/api/admin/product/" + s.product.ID.String()
+	categoryPath := "/api/admin/category/" + s.category.ID.String()
+
+	var category dto.ResponseCategory
+	s.Expect(http.StatusOK, http.MethodPatch, categoryPath, s.admin.Token,
+		dto.RequestUpdateCategory{Name: "Audio"}).Data(&category)
+	if category.Name != "Audio" {
+		t.Fatalf("category name = %q, want Audio", category.Name)
+	}
+	var product dto.ResponseProduct
+	s.Expect(http.StatusOK, http.MethodPatch, productPath, s.admin.Token,
+		dto.RequestUpdateProduct{Name: "Wireless earbuds", Price: 60}).Data(&product)
+	if product.Name != "Wireless earbuds" || product.Price != 60 || product.Stock != 5 {
+		t.Fatalf("updated product = %+v, want the new name and price with the same stock", product)
+	}
+
+	// Customers cannot manage the catalog
+	s.Expect(http.StatusForbidden, http.MethodPatch, productPath, s.customer.Token, dto.RequestUpdateProduct{Price: 1})
+	s.Expect(http.StatusForbidden, http.MethodPost, "/api/admin/category", s.customer.Token, dto.RequestCategory{Name: "Toys"})
+	s.Expect(http.StatusUnauthorized, http.MethodDelete, productPath, "", nil)
+
+	s.Expect(http.StatusOK, http.MethodDelete, productPath, s.admin.Token, nil)
+	s.Expect(http.StatusNotFound, http.MethodGet, "/api/product/"+s.product.ID.String(), "", nil)
+	s.Expect(http.StatusNotFound, http.MethodDelete, productPath, s.admin.Token, nil)
+	s.Expect(http.StatusOK, http.MethodDelete, categoryPath, s.admin.Token, nil)
+	s.Expect(http.StatusNotFound, http.MethodPatch, categoryPath, s.admin.Token, dto.RequestUpdateCategory{Name: "Audio"})
+}
+
+func TestAdminManagesUsers(t *testing.T) {
+	s := newShop(t, 5)
+	userPath := "/api/admin/users/" + s.customer.ID.String()
+
+	var users dto.ResponsePaginated[dto.ResponseAdminUser]
+	s.Get("/api/admin/users?role=customer", s.admin.Token).Data(&users)
+	if users.Meta.Total != 1 || users.List[0].ID != s.customer.ID {
+		t.Fatalf("customers = %+v, want only %s", users, s.customer.ID)
+	}
+	s.Expect(http.StatusBadRequest, http.MethodGet, "/api/admin/users?role=owner", s.admin.Token, nil)
+	s.Expect(http.StatusNotFound, http.MethodGet, "/api/admin/users/"+uuid.NewString(), s.admin.Token, nil)
+
+	var roles []dto.ResponseRole
+	s.Get("/api/admin/roles", s.admin.Token).Data(&roles)
+	if len(roles) != len(models.Roles) {
+		t.Fatalf("%d roles, want %d", len(roles), len(models.Roles))
+	}
+
+	// A catalog manager may edit products but not read orders
+	s.Expect(http.StatusOK, http.MethodPut, userPath+"/role", s.admin.Token, dto.RequestAssignRole{Role: models.CatalogManager})
+	s.Expect(http.StatusBadRequest, http.MethodPut, userPath+"/role", s.admin.Token, dto.RequestAssignRole{Role: "owner"})
+	manager := s.Login(s.customer.Email, s.customer.Password)
+	s.Expect(http.StatusOK, http.MethodPatch, "/api/admin/product/"+s.product.ID.String(), manager.Token, dto.RequestUpdateProduct{Stock: 8})
+	s.Expect(http.StatusForbidden, http.MethodGet, "/api/admin/orders", manager.Token, nil)
+	s.Expect(http.StatusOK, http.MethodPut, userPath+"/role", s.admin.Token, dto.RequestAssignRole{Role: models.Customer})
+
+	// Suspended accounts cannot log in until reactivated
+	var user dto.ResponseAdminUser
+	s.Expect(http.StatusOK, http.MethodPost, userPath+"/suspend", s.admin.Token,
+		dto.RequestSuspendUser{Reason: "chargebacks"}).Data(&user)
+	if user.SuspendedAt == nil || user.SuspensionReason != "chargebacks" {
+		t.Fatalf("suspended user = %+v, want the suspension recorded", user)
+	}
+	s.Expect(http.StatusForbidden, http.MethodPost, "/api/auth/login", "",
+		dto.RequestLogin{Email: s.customer.Email, Password: s.customer.Password})
+	s.Expect(http.StatusOK, http.MethodPost, userPath+"/reactivate", s.admin.Token, nil)
+	s.Login(s.customer.Email, s.customer.Password)
+
+	// A forced reset blocks password logins and emails a reset token
+	s.Mail.Reset()
+	s.Get(userPath, s.admin.Token)
+	s.Expect(http.StatusOK, http.MethodPost, userPath+"/force-password-reset", s.admin.Token, nil)
+	if _, ok := s.Mail.LastTo(s.customer.Email); !ok {
+		t.Fatal("no reset email sent")
+	}
+	s.Expect(http.StatusForbidden, http.MethodPost, "/api/auth/login", "",
+		dto.RequestLogin{Email: s.customer.Email, Password: s.customer.Password})
+
+	// Admins cannot manage themselves
+	s.Expect(http.StatusBadRequest, http.MethodPost, "/api/admin/users/"+s.admin.ID.String()+"/suspend", s.admin.Token,
+		dto.RequestSuspendUser{})
+
+	s.Expect(http.StatusOK, http.MethodDelete, userPath, s.admin.Token, nil)
+	s.Get(userPath, s.admin.Token).Data(&user)
+	if user.AnonymizedAt == nil {
+		t.Fatalf("deleted user = %+v, want it anonymized", user)
+	}
+}
+
+func TestAdminImpersonatesCustomer(t *testing.T) {
+	s := newShop(t, 5)
+
+	s.Expect(http.StatusBadRequest, http.MethodPost, "/api/admin/users/"+s.customer.ID.String()+"/impersonate", s.admin.Token,
+		dto.RequestImpersonate{})
+	var impersonation dto.ResponseImpersonation
+	s.Expect(http.StatusOK, http.MethodPost, "/api/admin/users/"+s.customer.ID.String()+"/impersonate", s.admin.Token,
+		dto.RequestImpersonate{Reason: "ticket 42"}).Data(&impersonation)
+
+	// Impersonation tokens read as the customer but cannot write
+	var me dto.ResponseUser
+	s.Get("/api/user/me", impersonation.AccessToken).Data(&me)
+	if me.ID != s.customer.ID {
+		t.Fatalf("impersonated user = %s, want %s", me.ID, s.customer.ID)
+	}
+	s.Expect(http.StatusForbidden, http.MethodPost, "/api/cart", impersonation.AccessToken,
+		dto.RequestAddProductToCart{ProductID: s.product.ID, Quantity: 1})
+	// Nor read the data export or the sessions of the customer
+	s.Expect(http.StatusForbidden, http.MethodGet, "/api/user/export", impersonation.AccessToken, nil)
+	s.Expect(http.StatusForbidden, http.MethodGet, "/API/User/Sessions/", impersonation.AccessToken, nil)
+	s.Get("/api/user/export", s.customer.Token)
+
+	var logs dto.ResponsePaginated[dto.ResponseImpersonationLog]
+	s.Get("/api/admin/impersonation-logs?user_id="+s.customer.ID.String(), s.admin.Token).Data(&logs)
+	if logs.Meta.Total != 4 {
+		t.Fatalf("%d impersonated requests logged, want 4", logs.Meta.Total)
+	}
+	blocked := 0
+	for _, entry := range logs.List {
+		if entry.Blocked {
+			blocked++
+		}
+	}
+	if blocked != 3 {
+		t.Fatalf("impersonation logs = %+v, want the 3 refused requests blocked", logs.List)
+	}
+}
+
+func TestAdminManagesAPIKeys(t *testing.T) {
+	s := newShop(t, 5)
+
+	var created dto.ResponseAPIKeySecret
+	s.Expect(http.StatusCreated, http.MethodPost, "/api/admin/api-keys", s.admin.Token,
+		dto.RequestCreateAPIKey{Name: "catalog sync", Scopes: []string{models.ScopeCatalogWrite}}).Data(&created)
+	s.Expect(http.StatusBadRequest, http.MethodPost, "/api/admin/api-keys", s.admin.Token,
+		dto.RequestCreateAPIKey{Name: "everything", Scopes: []string{"root"}})
+
+	// Keys are limited to their scopes
+	s.ExpectWithAPIKey(http.StatusCreated, http.MethodPost, "/api/admin/category", created.Key, dto.RequestCategory{Name: "Toys"})
+	s.ExpectWithAPIKey(http.StatusForbidden, http.MethodGet, "/api/admin/orders", created.Key, nil)
+
+	var keys dto.ResponsePaginated[dto.ResponseAPIKey]
+	s.Get("/api/admin/api-keys", s.admin.Token).Data(&keys)
+	if keys.Meta.Total != 1 || keys.List[0].LastUsedAt == nil {
+		t.Fatalf("api keys = %+v, want the used key", keys)
+	}
+
+	var rotated dto.ResponseAPIKeySecret
+	s.Expect(http.StatusOK, http.MethodPost, "/api/admin/api-keys/"+created.ID.String()+"/rotate", s.admin.Token, nil).Data(&rotated)
+	s.ExpectWithAPIKey(http.StatusUnauthorized, http.MethodPost, "/api/admin/category", created.Key, dto.RequestCategory{Name: "Games"})
+	s.ExpectWithAPIKey(http.StatusCreated, http.MethodPost, "/api/admin/category", rotated.Key, dto.RequestCategory{Name: "Games"})
+
+	s.Expect(http.StatusOK, http.MethodDelete, "/api/admin/api-keys/"+created.ID.String(), s.admin.Token, nil)
+	s.Expect(http.StatusNotFound, http.MethodDelete, "/api/admin/api-keys/"+uuid.NewString(), s.admin.Token, nil)
+	s.ExpectWithAPIKey(http.StatusUnauthorized, http.MethodPost, "/api/admin/category", rotated.Key, dto.RequestCategory{Name: "Books"})
+}
+
+func TestAdminUnlocksLogin(t *testing.T) {
+	s := newShop(t, 5)
+
+	s.Expect(http.StatusBadRequest, http.MethodPost, "/api/auth/login", "",
+		dto.RequestLogin{Email: s.customer.Email, Password: "wrong-password"})
+	s.Expect(http.StatusTooManyRequests, http.MethodPost, "/api/auth/login", "",
+		dto.RequestLogin{Email: s.customer.Email, Password: s.customer.Password})
+
+	s.Expect(http.StatusBadRequest, http.MethodPost, "/api/admin/lockouts/unlock", s.admin.Token, dto.RequestUnlockLogin{})
+	s.Expect(http.StatusOK, http.MethodPost, "/api/admin/lockouts/unlock", s.admin.Token, dto.RequestUnlockLogin{Email: s.customer.Email})
+	s.Login(s.customer.Email, s.customer.Password)
+
+	var events dto.ResponsePaginated[dto.ResponseSecurityEvent]
+	s.Get("/api/admin/lockouts/events", s.admin.Token).Data(&events)
+	if events.Meta.Total != 1 || events.List[0].Type != models.EventLockoutCleared || events.List[0].Email != s.customer.Email {
+		t.Fatalf("security events = %+v, want the unlock of %s", events.List, s.customer.Email)
+	}
+}
+
+func TestAdminRoutesRequireTwoFactor(t *testing.T) {
+	app := apptest.New(t)
+	if _, err := service.CreateAdminUser("Admin", "admin@example.com", "admin-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Until enrolled, admins only get password tokens which admin routes refuse
+	token := app.Login("admin@example.com", "admin-password").Token
+	app.Expect(http.StatusForbidden, http.MethodGet, "/api/admin/users", token, nil)
+	app.Get("/api/user/me", token)
+}
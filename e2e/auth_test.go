@@ -0,0 +1,281 @@
+package e2e_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/apptest"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/mail"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var resetToken = regexp.MustCompile(`Your reset token is: (\S+)`)
+
+func TestRegisterVerifyLoginRefreshLogout(t *testing.T) {
+	app := apptest.New(t)
+
+	app.Register("Jane", "jane@example.com", "jane-password")
+	app.Expect(http.StatusBadRequest, http.MethodPost, "/api/auth/register", "",
+		dto.RequestRegister{Name: "Jane", Email: "jane@example.com", Password: "another-password"})
+
+	// Unverified accounts can ask for a new link
+	app.Expect(http.StatusBadRequest, http.MethodGet, "/api/auth/verify", "", nil)
+	app.Expect(http.StatusBadRequest, http.MethodGet, "/api/auth/verify?token=invalid", "", nil)
+	app.Mail.Reset()
+	app.Expect(http.StatusOK, http.MethodPost, "/api/auth/verify/resend", "", dto.RequestEmail{Email: "jane@example.com"})
+	app.VerifyEmail("jane@example.com")
+
+	tokens := app.Login("jane@example.com", "jane-password")
+	var me dto.ResponseUser
+	app.Get("/api/user/me", tokens.Token).Data(&me)
+	if me.Email != "jane@example.com" || me.VerifiedAt == nil {
+		t.Fatalf("me = %+v, want the verified jane@example.com", me)
+	}
+
+	var refreshed dto.ResponseAuthToken
+	app.Expect(http.StatusOK, http.MethodPost, "/api/auth/refresh", "",
+		dto.RequestRefreshToken{RefreshToken: tokens.RefreshToken}).Decode(&refreshed)
+	app.Expect(http.StatusOK, http.MethodPost, "/api/auth/logout", refreshed.Token,
+		dto.RequestLogout{RefreshToken: refreshed.RefreshToken})
+	app.Expect(http.StatusUnauthorized, http.MethodGet, "/api/user/me", refreshed.Token, nil)
+	app.Expect(http.StatusUnauthorized, http.MethodPost, "/api/auth/refresh", "",
+		dto.RequestRefreshToken{RefreshToken: refreshed.RefreshToken})
+}
+
+func TestRefreshTokenReuseRevokesSessions(t *testing.T) {
+	app := apptest.New(t)
+	user := app.RegisterCustomer("Jane", "jane@example.com", "jane-password")
+
+	var refreshed dto.ResponseAuthToken
+	app.Expect(http.StatusOK, http.MethodPost, "/api/auth/refresh", "",
+		dto.RequestRefreshToken{RefreshToken: user.RefreshToken}).Decode(&refreshed)
+	app.Get("/api/user/me", refreshed.Token)
+
+	// A refresh token ended by logout is refused without signing out the other sessions
+	other := app.Login(user.Email, user.Password)
+	app.Expect(http.StatusOK, http.MethodPost, "/api/auth/logout", other.Token,
+		dto.RequestLogout{RefreshToken: other.RefreshToken})
+	app.Expect(http.StatusUnauthorized, http.MethodPost, "/api/auth/refresh", "",
+		dto.RequestRefreshToken{RefreshToken: other.RefreshToken})
+	app.Get("/api/user/me", refreshed.Token)
+
+	// Presenting a rotated refresh token again signs the user out everywhere
+	app.Expect(http.StatusUnauthorized, http.MethodPost, "/api/auth/refresh", "",
+		dto.RequestRefreshToken{RefreshToken: user.RefreshToken})
+	app.Expect(http.StatusUnauthorized, http.MethodGet, "/api/user/me", refreshed.Token, nil)
+	app.Expect(http.StatusUnauthorized, http.MethodPost, "/api/auth/refresh", "",
+		dto.RequestRefreshToken{RefreshToken: refreshed.RefreshToken})
+}
+
+func TestLoginFailures(t *testing.T) {
+	app := apptest.New(t)
+	app.RegisterCustomer("Jane", "jane@example.com", "jane-password")
+	expired := time.Now().Add(-2 * service.LoginAttemptWindow)
+	lockedUntil := time.Now().Add(time.Hour)
+	stale := []models.LoginAttempt{
+		{Key: "ip:192.0.2.1", Failures: 1, LastFailureAt: expired},
+		{Key: "ip:192.0.2.2", Failures: 9, LastFailureAt: expired, LockedUntil: &lockedUntil},
+	}
+	if err := app.DB.Create(&stale).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	app.Expect(http.StatusNotFound, http.MethodPost, "/api/auth/login", "",
+		dto.RequestLogin{Email: "nobody@example.com", Password: "whatever"})
+	app.Expect(http.StatusBadRequest, http.MethodPost, "/api/auth/login", "",
+		dto.RequestLogin{Email: "jane@example.com", Password: "wrong-password"})
+	// Failures slow down further attempts on the account
+	app.Expect(http.StatusTooManyRequests, http.MethodPost, "/api/auth/login", "",
+		dto.RequestLogin{Email: "jane@example.com", Password: "jane-password"})
+	app.Expect(http.StatusUnauthorized, http.MethodGet, "/api/user/me", "not-a-token", nil)
+
+	// Recording failures prunes the counters that expired, locks are kept until lifted
+	var keys []string
+	app.DB.Model(&models.LoginAttempt{}).Where("attempt_key LIKE ?", "ip:192.0.2.%").Pluck("attempt_key", &keys)
+	if len(keys) != 1 || keys[0] != "ip:192.0.2.2" {
+		t.Fatalf("stale login attempts left %v, want only the locked one", keys)
+	}
+}
+
+func TestForgotAndResetPassword(t *testing.T) {
+	app := apptest.New(t)
+	user := app.RegisterCustomer("Jane", "jane@example.com", "jane-password")
+
+	// Unknown addresses get the same answer
+	app.Expect(http.StatusOK, http.MethodPost, "/api/auth/forgot-password", "", dto.RequestEmail{Email: "nobody@example.com"})
+	app.Expect(http.StatusOK, http.MethodPost, "/api/auth/forgot-password", "", dto.RequestEmail{Email: user.Email})
+	msg, ok := app.Mail.LastTo(user.Email)
+	match := resetToken.FindStringSubmatch(msg.Body)
+	if !ok || match == nil {
+		t.Fatalf("no reset token emailed to %s", user.Email)
+	}
+
+	app.Expect(http.StatusBadRequest, http.MethodPost, "/api/auth/reset-password", "",
+		dto.RequestResetPassword{Token: "invalid", NewPassword: "new-password"})
+	app.Expect(http.StatusOK, http.MethodPost, "/api/auth/reset-password", "",
+		dto.RequestResetPassword{Token: match[1], NewPassword: "new-password"})
+	// Reset links are single use and sign the user out
+	app.Expect(http.StatusBadRequest, http.MethodPost, "/api/auth/reset-password", "",
+		dto.RequestResetPassword{Token: match[1], NewPassword: "other-password"})
+	app.Expect(http.StatusUnauthorized, http.MethodGet, "/api/user/me", user.Token, nil)
+
+	app.Login(user.Email, "new-password")
+}
+
+func TestLoginUpgradesBcryptPasswordHashes(t *testing.T) {
+	app := apptest.New(t)
+	user := app.RegisterCustomer("Jane", "jane@example.com", "jane-password")
+	// Accounts created before argon2id have bcrypt hashes
+	legacy, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("password", string(legacy)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	app.Login(user.Email, user.Password)
+	var stored models.User
+	app.DB.First(&stored, "id = ?", user.ID)
+	if !strings.HasPrefix(stored.Password, "$argon2id$") {
+		t.Fatalf("password hash %q after login, want argon2id", stored.Password)
+	}
+	// The new hash keeps the same password
+	app.Login(user.Email, user.Password)
+}
+
+// failingSender fails every delivery, like an unreachable mail server
+type failingSender struct{}
+
+func (failingSender) Send(mail.Message) error { return errors.New("mail server unreachable") }
+
+// blockingSender holds every delivery until released, like a slow mail server
+type blockingSender struct {
+	release chan struct{}
+}
+
+func (s blockingSender) Send(mail.Message) error {
+	<-s.release
+	return nil
+}
+
+// answeredBeforeSending fails unless the API answers a request for an email
+// to email while the delivery is still held
+func answeredBeforeSending(t *testing.T, app *apptest.App, path, email string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"email":"`+email+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	// The timeout is in milliseconds, the email is held for longer
+	resp, err := app.Fiber.Test(req, 1000)
+	if err != nil {
+		t.Fatalf("POST %s for %s waited for the email: %v", path, email, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST %s for %s: status %d, want 200", path, email, resp.StatusCode)
+	}
+}
+
+func TestEmailEndpointsAnswerBeforeSending(t *testing.T) {
+	app := apptest.New(t)
+	user := app.RegisterCustomer("Jane", "jane@example.com", "jane-password")
+	app.Register("John", "john@example.com", "john-password")
+	sender := blockingSender{release: make(chan struct{})}
+	mail.SetDefault(sender)
+	defer close(sender.release)
+
+	// Registered addresses take as long as unknown ones, the email is sent afterwards
+	answeredBeforeSending(t, app, "/api/auth/forgot-password", user.Email)
+	answeredBeforeSending(t, app, "/api/auth/verify/resend", "john@example.com")
+}
+
+func TestEmailEndpointsHideDeliveryFailures(t *testing.T) {
+	app := apptest.New(t)
+	user := app.RegisterCustomer("Jane", "jane@example.com", "jane-password")
+	app.Register("John", "john@example.com", "john-password")
+	logs := app.CaptureLogs()
+	mail.SetDefault(failingSender{})
+
+	// A failed delivery answers like an unknown address, only the logs tell
+	app.Expect(http.StatusOK, http.MethodPost, "/api/auth/forgot-password", "", dto.RequestEmail{Email: user.Email})
+	app.Expect(http.StatusOK, http.MethodPost, "/api/auth/verify/resend", "", dto.RequestEmail{Email: "john@example.com"})
+	for _, msg := range []string{"failed to send password reset email", "failed to send verification email"} {
+		if !strings.Contains(logs.String(), msg) {
+			t.Fatalf("%s not logged:\n%s", msg, logs)
+		}
+	}
+}
+
+func TestTwoFactorAuthentication(t *testing.T) {
+	app := apptest.New(t)
+	user := app.RegisterCustomer("Jane", "jane@example.com", "jane-password")
+	app.EnableTwoFactor(user)
+
+	var challenge dto.ResponseLoginChallenge
+	app.Expect(http.StatusOK, http.MethodPost, "/api/auth/login", "",
+		dto.RequestLogin{Email: user.Email, Password: user.Password}).Decode(&challenge)
+	var tokens dto.ResponseAuthToken
+	app.Expect(http.StatusOK, http.MethodPost, "/api/auth/login/2fa", "",
+		dto.RequestLoginTwoFactor{ChallengeToken: challenge.ChallengeToken, Code: user.RecoveryCodes[0]}).Decode(&tokens)
+	user.Token = tokens.Token
+	// Login challenges complete a single login
+	app.Expect(http.StatusUnauthorized, http.MethodPost, "/api/auth/login/2fa", "",
+		dto.RequestLoginTwoFactor{ChallengeToken: challenge.ChallengeToken, Code: user.RecoveryCodes[1]})
+	// Recovery codes are single use
+	app.Expect(http.StatusOK, http.MethodPost, "/api/auth/login", "",
+		dto.RequestLogin{Email: user.Email, Password: user.Password}).Decode(&challenge)
+	app.Expect(http.StatusUnauthorized, http.MethodPost, "/api/auth/login/2fa", "",
+		dto.RequestLoginTwoFactor{ChallengeToken: challenge.ChallengeToken, Code: user.RecoveryCodes[0]})
+
+	// The code of the next step was not used yet, unlike the enrollment one
+	code, err := totp.GenerateCode(user.TOTPSecret, totp.Step(time.Now())+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recovery dto.ResponseRecoveryCodes
+	app.Expect(http.StatusOK, http.MethodPost, "/api/user/2fa/recovery-codes", user.Token,
+		dto.RequestTwoFactorCode{Code: code}).Data(&recovery)
+	if len(recovery.RecoveryCodes) == 0 || recovery.RecoveryCodes[0] == user.RecoveryCodes[1] {
+		t.Fatalf("recovery codes were not regenerated: %v", recovery.RecoveryCodes)
+	}
+
+	app.Expect(http.StatusBadRequest, http.MethodPost, "/api/user/2fa/disable", user.Token,
+		dto.RequestTwoFactorCode{Code: user.RecoveryCodes[1]})
+	app.Expect(http.StatusOK, http.MethodPost, "/api/user/2fa/disable", user.Token,
+		dto.RequestTwoFactorCode{Code: recovery.RecoveryCodes[0]})
+	var me dto.ResponseUser
+	app.Get("/api/user/me", user.Token).Data(&me)
+	if me.TwoFactorEnabled {
+		t.Fatal("two-factor authentication is still enabled")
+	}
+}
+
+func TestOIDCLoginOfUnknownProvider(t *testing.T) {
+	app := apptest.New(t)
+
+	app.Expect(http.StatusNotFound, http.MethodGet, "/api/auth/oidc/unknown/login", "", nil)
+	app.Expect(http.StatusBadRequest, http.MethodGet, "/api/auth/oidc/unknown/callback?code=x&state=y", "", nil)
+}
+
+func TestJWKS(t *testing.T) {
+	app := apptest.New(t)
+
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+		} `json:"keys"`
+	}
+	app.Get("/.well-known/jwks.json", "").Decode(&jwks)
+	if len(jwks.Keys) == 0 || jwks.Keys[0].Kid == "" {
+		t.Fatalf("jwks = %+v, want a signing key", jwks)
+	}
+}
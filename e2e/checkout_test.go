@@ -0,0 +1,220 @@
+package e2e_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/apptest"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+	"github.com/google/uuid"
+)
+
+// shop is an app with an admin, a customer and one product in stock
+type shop struct {
+	*apptest.App
+	admin    *apptest.User
+	customer *apptest.User
+	category dto.ResponseCategory
+	product  dto.ResponseProduct
+}
+
+func newShop(t *testing.T, stock int, configure ...func(auth *config.AuthSettings)) *shop {
+	t.Helper()
+	app := apptest.New(t, configure...)
+	s := &shop{App: app}
+	s.admin = app.CreateAdmin("Admin", "admin@example.com", "admin-password")
+	s.customer = app.RegisterCustomer("Customer", "customer@example.com", "customer-password")
+	s.category = app.CreateCategory(s.admin, "Electronics")
+	s.product = app.CreateProduct(s.admin, s.category.ID, "Earbuds", 50, stock)
+	return s
+}
+
+func webhookPath(paymentID uuid.UUID, status, otp string) string {
+	query := url.Values{"paymentId": {paymentID.String()}, "status": {status}, "otp": {otp}}
+	return "/api/webhook/payment?" + query.Encode()
+}
+
+func (s *shop) stock(t *testing.T) int {
+	t.Helper()
+	var product dto.ResponseProduct
+	s.Get("/api/product/"+s.product.ID.String(), "").Data(&product)
+	return product.Stock
+}
+
+func (s *shop) orders(t *testing.T) []dto.ResponseOrder {
+	t.Helper()
+	var orders []dto.ResponseOrder
+	s.Get("/api/order", s.customer.Token).Data(&orders)
+	return orders
+}
+
+func TestBrowseCartCheckoutAndPay(t *testing.T) {
+	s := newShop(t, 10)
+
+	// Browse
+	var products dto.ResponsePaginated[dto.ResponseProduct]
+	s.Get("/api/product?category_id="+s.category.ID.String(), "").Data(&products)
+	if products.Meta.Total != 1 || len(products.List) != 1 || products.List[0].ID != s.product.ID {
+		t.Fatalf("products of the category = %+v, want only %s", products, s.product.ID)
+	}
+	if products.List[0].Category.Name != "Electronics" {
+		t.Fatalf("product category = %q, want Electronics", products.List[0].Category.Name)
+	}
+	var categories dto.ResponsePaginated[dto.ResponseCategory]
+	s.Get("/api/category", "").Data(&categories)
+	if categories.Meta.Total != 1 {
+		t.Fatalf("categories total = %d, want 1", categories.Meta.Total)
+	}
+
+	// Cart
+	s.AddToCart(s.customer, s.product.ID, 2)
+	s.AddToCart(s.customer, s.product.ID, 1)
+	var cart dto.ResponseCart
+	s.Get("/api/cart", s.customer.Token).Data(&cart)
+	if cart.TotalAmount != 150 || len(cart.CartItems.List) != 1 || cart.CartItems.List[0].Quantity != 3 {
+		t.Fatalf("cart = %+v, want one item of 3 for 150", cart)
+	}
+	var item dto.ResponseCartItem
+	s.Get("/api/cart/"+cart.CartItems.List[0].ID.String(), s.customer.Token).Data(&item)
+	if item.ProductID != s.product.ID {
+		t.Fatalf("cart item product = %s, want %s", item.ProductID, s.product.ID)
+	}
+
+	// Checkout
+	checkout := s.Checkout(s.customer, "cc")
+	if checkout.TotalAmount != 150 || checkout.Otp == "" {
+		t.Fatalf("checkout = %+v, want 150 with an OTP", checkout)
+	}
+	if stock := s.stock(t); stock != 7 {
+		t.Fatalf("stock after checkout = %d, want 7", stock)
+	}
+	s.Get("/api/cart", s.customer.Token).Data(&cart)
+	if len(cart.CartItems.List) != 0 {
+		t.Fatalf("cart after checkout has %d items, want none", len(cart.CartItems.List))
+	}
+	orders := s.orders(t)
+	if len(orders) != 1 || orders[0].ID != checkout.ID || orders[0].Status != "pending" {
+		t.Fatalf("orders = %+v, want the pending order %s", orders, checkout.ID)
+	}
+
+	// Payment
+	s.Get(webhookPath(checkout.PaymentID, "paid", checkout.Otp), "")
+	orders = s.orders(t)
+	if orders[0].Status != "paid" {
+		t.Fatalf("order status after payment = %q, want paid", orders[0].Status)
+	}
+
+	var all dto.ResponsePaginated[dto.ResponseOrder]
+	s.Get("/api/admin/orders", s.admin.Token).Data(&all)
+	if all.Meta.Total != 1 || all.List[0].Status != "paid" {
+		t.Fatalf("admin orders = %+v, want the paid order", all)
+	}
+}
+
+func TestAddToCartWithInsufficientStock(t *testing.T) {
+	s := newShop(t, 2)
+
+	s.Expect(http.StatusConflict, http.MethodPost, "/api/cart", s.customer.Token,
+		dto.RequestAddProductToCart{ProductID: s.product.ID, Quantity: 3})
+	s.Expect(http.StatusNotFound, http.MethodPost, "/api/cart", s.customer.Token,
+		dto.RequestAddProductToCart{ProductID: uuid.New(), Quantity: 1})
+	s.Expect(http.StatusBadRequest, http.MethodPost, "/api/cart", s.customer.Token,
+		dto.RequestAddProductToCart{ProductID: s.product.ID, Quantity: 0})
+}
+
+func TestCheckoutWithInsufficientStockChangesNothing(t *testing.T) {
+	s := newShop(t, 5)
+	s.AddToCart(s.customer, s.product.ID, 4)
+
+	// The stock drops below the cart quantity before checkout
+	s.Expect(http.StatusOK, http.MethodPatch, "/api/admin/product/"+s.product.ID.String(), s.admin.Token,
+		dto.RequestUpdateProduct{Stock: 3})
+	s.Expect(http.StatusConflict, http.MethodPost, "/api/order/checkout", s.customer.Token,
+		dto.RequestCreatePayment{Method: "cc"})
+
+	if stock := s.stock(t); stock != 3 {
+		t.Fatalf("stock after failed checkout = %d, want 3", stock)
+	}
+	if orders := s.orders(t); len(orders) != 0 {
+		t.Fatalf("failed checkout created orders %+v", orders)
+	}
+	var cart dto.ResponseCart
+	s.Get("/api/cart", s.customer.Token).Data(&cart)
+	if len(cart.CartItems.List) != 1 {
+		t.Fatalf("failed checkout emptied the cart: %+v", cart)
+	}
+}
+
+func TestCheckoutFailures(t *testing.T) {
+	s := newShop(t, 5)
+
+	s.Expect(http.StatusNotFound, http.MethodPost, "/api/order/checkout", s.customer.Token,
+		dto.RequestCreatePayment{Method: "cc"})
+	s.AddToCart(s.customer, s.product.ID, 1)
+	s.Expect(http.StatusBadRequest, http.MethodPost, "/api/order/checkout", s.customer.Token,
+		dto.RequestCreatePayment{Method: "cash"})
+
+	var cart dto.ResponseCart
+	s.Get("/api/cart", s.customer.Token).Data(&cart)
+	s.Expect(http.StatusOK, http.MethodDelete, "/api/cart/"+cart.CartItems.List[0].ID.String(), s.customer.Token, nil)
+	s.Expect(http.StatusBadRequest, http.MethodPost, "/api/order/checkout", s.customer.Token,
+		dto.RequestCreatePayment{Method: "cc"})
+}
+
+func TestCheckoutRequiresVerifiedEmail(t *testing.T) {
+	s := newShop(t, 5)
+	id := s.Register("Unverified", "unverified@example.com", "unverified-password")
+	user := &apptest.User{ID: id, Email: "unverified@example.com", Token: s.Login("unverified@example.com", "unverified-password").Token}
+
+	s.AddToCart(user, s.product.ID, 1)
+	s.Expect(http.StatusForbidden, http.MethodPost, "/api/order/checkout", user.Token,
+		dto.RequestCreatePayment{Method: "cc"})
+
+	s.VerifyEmail(user.Email)
+	s.Checkout(user, "debit")
+}
+
+func TestCheckoutOfUnverifiedAccountsWhenAllowed(t *testing.T) {
+	s := newShop(t, 5, func(auth *config.AuthSettings) { auth.RequireVerifiedEmailForCheckout = false })
+	s.Register("Unverified", "unverified@example.com", "unverified-password")
+	user := &apptest.User{Token: s.Login("unverified@example.com", "unverified-password").Token}
+
+	s.AddToCart(user, s.product.ID, 1)
+	s.Checkout(user, "cc")
+}
+
+func TestPaymentWebhookRejectsBadOTP(t *testing.T) {
+	s := newShop(t, 5)
+	s.AddToCart(s.customer, s.product.ID, 1)
+	checkout := s.Checkout(s.customer, "cc")
+
+	s.Expect(http.StatusUnauthorized, http.MethodGet, webhookPath(checkout.PaymentID, "paid", "wrong-otp"), "", nil)
+	s.Expect(http.StatusNotFound, http.MethodGet, webhookPath(uuid.New(), "paid", checkout.Otp), "", nil)
+	s.Expect(http.StatusBadRequest, http.MethodGet, "/api/webhook/payment?paymentId=not-a-uuid&status=paid&otp=x", "", nil)
+	if status := s.orders(t)[0].Status; status != "pending" {
+		t.Fatalf("order status after rejected webhooks = %q, want pending", status)
+	}
+
+	// A failed payment leaves the order pending
+	s.Get(webhookPath(checkout.PaymentID, "failed", checkout.Otp), "")
+	if status := s.orders(t)[0].Status; status != "pending" {
+		t.Fatalf("order status after a failed payment = %q, want pending", status)
+	}
+}
+
+func TestCartItemsOfOtherUsers(t *testing.T) {
+	s := newShop(t, 5)
+	other := s.RegisterCustomer("Other", "other@example.com", "other-password")
+	s.AddToCart(s.customer, s.product.ID, 1)
+
+	var cart dto.ResponseCart
+	s.Get("/api/cart", s.customer.Token).Data(&cart)
+	itemPath := "/api/cart/" + cart.CartItems.List[0].ID.String()
+
+	s.Expect(http.StatusNotFound, http.MethodDelete, itemPath, other.Token, nil)
+	s.Expect(http.StatusOK, http.MethodDelete, itemPath, s.customer.Token, nil)
+	s.Expect(http.StatusNotFound, http.MethodDelete, itemPath, s.customer.Token, nil)
+	s.Expect(http.StatusNotFound, http.MethodGet, itemPath, s.customer.Token, nil)
+}
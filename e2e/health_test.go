@@ -0,0 +1,52 @@
+package e2e_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/apptest"
+)
+
+func TestHealthAndReadiness(t *testing.T) {
+	app := apptest.New(t)
+
+	app.Get("/healthz", "")
+	app.Get("/readyz", "")
+
+	// A rolled back migration makes the instance unready until it is applied
+	if err := app.DB.Exec("DELETE FROM schema_migrations WHERE version = (SELECT MAX(version) FROM schema_migrations)").Error; err != nil {
+		t.Fatal(err)
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	app.Expect(http.StatusServiceUnavailable, http.MethodGet, "/readyz", "", nil).Decode(&body)
+	if body.Error != "migrations pending" {
+		t.Fatalf("readiness error %q, want migrations pending", body.Error)
+	}
+
+	// A database never migrated has every migration pending, and probing it
+	// does not create the schema table
+	if err := app.DB.Migrator().DropTable("schema_migrations"); err != nil {
+		t.Fatal(err)
+	}
+	app.Expect(http.StatusServiceUnavailable, http.MethodGet, "/readyz", "", nil).Decode(&body)
+	if body.Error != "migrations pending" {
+		t.Fatalf("readiness error %q, want migrations pending", body.Error)
+	}
+	if app.DB.Migrator().HasTable("schema_migrations") {
+		t.Fatal("readiness probe created the schema_migrations table")
+	}
+
+	// Without a database the process is still alive, but not ready
+	sqlDB, err := app.DB.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Close()
+	app.Get("/healthz", "")
+	app.Expect(http.StatusServiceUnavailable, http.MethodGet, "/readyz", "", nil).Decode(&body)
+	if body.Error != "database unreachable" {
+		t.Fatalf("readiness error %q, want database unreachable", body.Error)
+	}
+}
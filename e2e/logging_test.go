@@ -0,0 +1,127 @@
+package e2e_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/apptest"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/middleware"
+	"github.com/google/uuid"
+)
+
+func withRequestID(id string) http.Header {
+	return http.Header{middleware.RequestIDHeader: {id}}
+}
+
+func errorRequestID(t *testing.T, resp *apptest.Response) string {
+	t.Helper()
+	var body struct {
+		RequestID string `json:"request_id"`
+	}
+	resp.Decode(&body)
+	return body.RequestID
+}
+
+// logLines parses the JSON lines logged to buf
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("log line %s is not JSON: %v", scanner.Bytes(), err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestRequestIDs(t *testing.T) {
+	app := apptest.New(t)
+
+	// IDs set by clients or proxies are kept
+	resp := app.Send(http.MethodGet, "/api/cart", withRequestID("edge-42"), nil)
+	if resp.Status != http.StatusUnauthorized || resp.Header.Get(middleware.RequestIDHeader) != "edge-42" {
+		t.Fatalf("status %d and request ID %q, want 401 and edge-42", resp.Status, resp.Header.Get(middleware.RequestIDHeader))
+	}
+	if id := errorRequestID(t, resp); id != "edge-42" {
+		t.Fatalf("error response request_id = %q, want edge-42", id)
+	}
+
+	// Others are replaced, so they cannot forge log lines
+	resp = app.Send(http.MethodGet, "/api/cart", withRequestID("forged\" id"), nil)
+	if _, err := uuid.Parse(resp.Header.Get(middleware.RequestIDHeader)); err != nil {
+		t.Fatalf("request ID %q, want a generated UUID", resp.Header.Get(middleware.RequestIDHeader))
+	}
+
+	// Errors of Fiber itself are answered the same way
+	resp = app.Expect(http.StatusNotFound, http.MethodGet, "/api/unknown", "", nil)
+	if id := errorRequestID(t, resp); id == "" || id != resp.Header.Get(middleware.RequestIDHeader) {
+		t.Fatalf("error response request_id = %q, want %q", id, resp.Header.Get(middleware.RequestIDHeader))
+	}
+
+	// Successful responses only carry the header
+	resp = app.Get("/api/product", "")
+	if resp.Header.Get(middleware.RequestIDHeader) == "" || bytes.Contains(resp.Body, []byte("request_id")) {
+		t.Fatalf("success response header %v and body %s, want the ID in the header only", resp.Header, resp.Body)
+	}
+}
+
+func TestLogsCarryRequestAndUser(t *testing.T) {
+	s := newShop(t, 5)
+	logs := s.CaptureLogs()
+
+	header := withRequestID("cart-1")
+	header.Set("Authorization", "Bearer "+s.customer.Token)
+	if resp := s.Send(http.MethodGet, "/api/cart", header, nil); resp.Status != http.StatusOK {
+		t.Fatalf("GET /api/cart: status %d: %s", resp.Status, resp.Body)
+	}
+
+	var statements int
+	var request map[string]interface{}
+	for _, line := range logLines(t, logs) {
+		if line["request_id"] != "cart-1" {
+			continue
+		}
+		if line["user_id"] != s.customer.ID.String() || line["route"] != "/api/cart/" || line["method"] != http.MethodGet {
+			t.Fatalf("log line %v, want the user, route and method of the request", line)
+		}
+		switch line["msg"] {
+		case "sql statement":
+			statements++
+		case "request":
+			request = line
+		}
+	}
+	if statements == 0 {
+		t.Fatal("no SQL statement logged with the request ID")
+	}
+	if request == nil || request["status"] != float64(http.StatusOK) || request["path"] != "/api/cart" {
+		t.Fatalf("request log = %v, want GET /api/cart answered with 200", request)
+	}
+}
+
+func TestLogsLeaveOutSecrets(t *testing.T) {
+	s := newShop(t, 5)
+	s.AddToCart(s.customer, s.product.ID, 1)
+	logs := s.CaptureLogs()
+
+	s.Login(s.customer.Email, s.customer.Password)
+	s.Expect(http.StatusBadRequest, http.MethodPost, "/api/auth/login", "",
+		dto.RequestLogin{Email: s.customer.Email, Password: "wrong-password"})
+	checkout := s.Checkout(s.customer, "cc")
+	s.Get(webhookPath(checkout.PaymentID, "paid", checkout.Otp), "")
+
+	for _, secret := range []string{s.customer.Password, "wrong-password", checkout.Otp, s.customer.Token} {
+		if strings.Contains(logs.String(), secret) {
+			t.Fatalf("logs contain %q:\n%s", secret, logs)
+		}
+	}
+}
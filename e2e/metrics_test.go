@@ -0,0 +1,79 @@
+package e2e_test
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+)
+
+// metrics scrapes /metrics and returns the value of each series, keyed by
+// its name and labels as written in the text format
+func (s *shop) metrics(t *testing.T) map[string]float64 {
+	t.Helper()
+	resp := s.Get("/metrics", "")
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Fatalf("metrics content type %q, want the text format", resp.Header.Get("Content-Type"))
+	}
+	series := map[string]float64{}
+	scanner := bufio.NewScanner(bytes.NewReader(resp.Body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		value, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("metric line %q: %v", line, err)
+		}
+		series[line[:i]] = value
+	}
+	return series
+}
+
+func TestMetrics(t *testing.T) {
+	s := newShop(t, 3)
+	other := s.RegisterCustomer("Other", "other@example.com", "other-password")
+	// Counters are shared by every app of the process, only their increase is checked
+	before := s.metrics(t)
+
+	s.AddToCart(s.customer, s.product.ID, 2)
+	s.AddToCart(s.customer, s.product.ID, 1)
+	s.AddToCart(other, s.product.ID, 1)
+	s.Expect(http.StatusConflict, http.MethodPost, "/api/cart", other.Token,
+		dto.RequestAddProductToCart{ProductID: s.product.ID, Quantity: 4})
+	paid := s.Checkout(s.customer, "cc")
+	s.Expect(http.StatusConflict, http.MethodPost, "/api/order/checkout", other.Token,
+		dto.RequestCreatePayment{Method: "cc"})
+	s.Get(webhookPath(paid.PaymentID, "paid", paid.Otp), "")
+	s.Expect(http.StatusNotFound, http.MethodGet, "/wp-login.php", "", nil)
+
+	after := s.metrics(t)
+	for series, want := range map[string]float64{
+		`store_carts_created_total`:                                                   2,
+		`store_stock_outs_total{stage="cart"}`:                                        1,
+		`store_stock_outs_total{stage="checkout"}`:                                    1,
+		`store_orders_checked_out_total`:                                              1,
+		`store_payments_total{status="paid"}`:                                         1,
+		`store_payments_total{status="failed"}`:                                       0,
+		`http_requests_total{method="POST",route="/api/cart/",status="200"}`:          3,
+		`http_requests_total{method="POST",route="/api/order/checkout",status="409"}`: 1,
+		`http_requests_total{method="GET",route="unmatched",status="404"}`:            1,
+		`http_request_duration_seconds_count{method="POST",route="/api/cart/"}`:       4,
+	} {
+		if got := after[series] - before[series]; got != want {
+			t.Errorf("%s increased by %v, want %v", series, got, want)
+		}
+	}
+	if after[`db_query_duration_seconds_count{operation="create",table="orders"}`] == 0 {
+		t.Error("no order insert timed in db_query_duration_seconds")
+	}
+	if _, ok := after[`go_sql_open_connections{db_name="sqlite"}`]; !ok {
+		t.Error("connection pool stats missing")
+	}
+}
@@ -0,0 +1,99 @@
+package e2e_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/apptest"
+)
+
+// covered maps every route of the API to the test exercising it. Adding a
+// route without a test here fails TestEveryRouteIsCovered.
+var covered = map[string]string{
+	"GET /.well-known/jwks.json": "TestJWKS",
+	"GET /metrics":               "TestMetrics",
+	"GET /healthz":               "TestHealthAndReadiness",
+	"GET /readyz":                "TestHealthAndReadiness",
+
+	"POST /api/auth/register":               "TestRegisterVerifyLoginRefreshLogout",
+	"GET /api/auth/verify":                  "TestRegisterVerifyLoginRefreshLogout",
+	"POST /api/auth/verify/resend":          "TestRegisterVerifyLoginRefreshLogout",
+	"POST /api/auth/login":                  "TestLoginFailures",
+	"POST /api/auth/login/2fa":              "TestTwoFactorAuthentication",
+	"POST /api/auth/refresh":                "TestRefreshTokenReuseRevokesSessions",
+	"POST /api/auth/logout":                 "TestRegisterVerifyLoginRefreshLogout",
+	"POST /api/auth/forgot-password":        "TestForgotAndResetPassword",
+	"POST /api/auth/reset-password":         "TestForgotAndResetPassword",
+	"GET /api/auth/oidc/:provider/login":    "TestOIDCLoginOfUnknownProvider",
+	"GET /api/auth/oidc/:provider/callback": "TestOIDCLoginOfUnknownProvider",
+
+	"GET /api/user/me":                  "TestRegisterVerifyLoginRefreshLogout",
+	"DELETE /api/user/me":               "TestExportAndDeleteAccount",
+	"GET /api/user/export":              "TestExportAndDeleteAccount",
+	"PATCH /api/user/update":            "TestUpdateProfileAndPassword",
+	"PATCH /api/user/change-password":   "TestUpdateProfileAndPassword",
+	"POST /api/user/2fa/setup":          "TestTwoFactorAuthentication",
+	"POST /api/user/2fa/confirm":        "TestTwoFactorAuthentication",
+	"POST /api/user/2fa/disable":        "TestTwoFactorAuthentication",
+	"POST /api/user/2fa/recovery-codes": "TestTwoFactorAuthentication",
+	"GET /api/user/sessions":            "TestSessions",
+	"DELETE /api/user/sessions":         "TestSessions",
+	"DELETE /api/user/sessions/:id":     "TestSessions",
+
+	"GET /api/product/":        "TestBrowseCartCheckoutAndPay",
+	"GET /api/product/:id":     "TestBrowseCartCheckoutAndPay",
+	"GET /api/category/":       "TestBrowseCartCheckoutAndPay",
+	"GET /api/cart/":           "TestBrowseCartCheckoutAndPay",
+	"POST /api/cart/":          "TestAddToCartWithInsufficientStock",
+	"GET /api/cart/:id":        "TestCartItemsOfOtherUsers",
+	"DELETE /api/cart/:id":     "TestCartItemsOfOtherUsers",
+	"GET /api/order/":          "TestBrowseCartCheckoutAndPay",
+	"POST /api/order/checkout": "TestCheckoutFailures",
+	"GET /api/webhook/payment": "TestPaymentWebhookRejectsBadOTP",
+
+	"POST /api/admin/product":                        "TestAdminManagesCatalog",
+	"PATCH /api/admin/product/:id":                   "TestAdminManagesCatalog",
+	"DELETE /api/admin/product/:id":                  "TestAdminManagesCatalog",
+	"POST /api/admin/category":                       "TestAdminManagesCatalog",
+	"PATCH /api/admin/category/:id":                  "TestAdminManagesCatalog",
+	"DELETE /api/admin/category/:id":                 "TestAdminManagesCatalog",
+	"GET /api/admin/orders":                          "TestBrowseCartCheckoutAndPay",
+	"POST /api/admin/lockouts/unlock":                "TestAdminUnlocksLogin",
+	"GET /api/admin/lockouts/events":                 "TestAdminUnlocksLogin",
+	"POST /api/admin/api-keys":                       "TestAdminManagesAPIKeys",
+	"GET /api/admin/api-keys":                        "TestAdminManagesAPIKeys",
+	"POST /api/admin/api-keys/:id/rotate":            "TestAdminManagesAPIKeys",
+	"DELETE /api/admin/api-keys/:id":                 "TestAdminManagesAPIKeys",
+	"GET /api/admin/impersonation-logs":              "TestAdminImpersonatesCustomer",
+	"GET /api/admin/roles":                           "TestAdminManagesUsers",
+	"GET /api/admin/users":                           "TestAdminManagesUsers",
+	"GET /api/admin/users/:id":                       "TestAdminManagesUsers",
+	"DELETE /api/admin/users/:id":                    "TestAdminManagesUsers",
+	"PUT /api/admin/users/:id/role":                  "TestAdminManagesUsers",
+	"POST /api/admin/users/:id/impersonate":          "TestAdminImpersonatesCustomer",
+	"POST /api/admin/users/:id/suspend":              "TestAdminManagesUsers",
+	"POST /api/admin/users/:id/reactivate":           "TestAdminManagesUsers",
+	"POST /api/admin/users/:id/force-password-reset": "TestAdminManagesUsers",
+}
+
+func TestEveryRouteIsCovered(t *testing.T) {
+	app := apptest.New(t)
+
+	registered := map[string]bool{}
+	for _, route := range app.Fiber.GetRoutes(true) {
+		// Fiber adds a HEAD route for every GET
+		if route.Method == http.MethodHead {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if _, ok := covered[key]; !ok {
+			t.Errorf("route %s has no end-to-end test, add one and list it in covered", key)
+		}
+	}
+	for key := range covered {
+		if !registered[key] {
+			t.Errorf("covered lists %s which is not a route anymore", key)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package e2e_test
+
+import (
+	"testing"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/apptest"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+)
+
+func TestSeedFileIsIdempotent(t *testing.T) {
+	app := apptest.New(t)
+
+	first, err := database.SeedFile("../fixtures/demo.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Created == 0 {
+		t.Fatalf("first seed = %+v, want created records", first)
+	}
+
+	second, err := database.SeedFile("../fixtures/demo.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Created != 0 || second.Updated != 0 {
+		t.Fatalf("second seed = %+v, want nothing created or updated", second)
+	}
+
+	// Products are matched by slug, so a product renamed with another case or
+	// punctuation is updated rather than created again
+	var before int64
+	app.DB.Model(&models.Product{}).Count(&before)
+	if err := app.DB.Model(&models.Product{}).Where("name = ?", "USB-C Charger 65W").Update("name", "usb c charger 65w").Error; err != nil {
+		t.Fatal(err)
+	}
+	third, err := database.SeedFile("../fixtures/demo.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var after int64
+	app.DB.Model(&models.Product{}).Count(&after)
+	if third.Created != 0 || third.Updated != 1 || after != before {
+		t.Fatalf("seed after a rename = %+v with %d products, want 1 update of the %d products", third, after, before)
+	}
+}
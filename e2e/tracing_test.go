@@ -0,0 +1,103 @@
+package e2e_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	callerTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	callerSpanID  = "00f067aa0ba902b7"
+)
+
+// spanNamed returns the span named name, failing when there is none
+func spanNamed(t *testing.T, spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+	t.Fatalf("no span named %s", name)
+	return nil
+}
+
+// assertChild fails unless child was started in parent
+func assertChild(t *testing.T, parent, child sdktrace.ReadOnlySpan) {
+	t.Helper()
+	if child.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("span %s has parent %s, want %s", child.Name(), child.Parent().SpanID(), parent.Name())
+	}
+}
+
+func TestCheckoutIsTraced(t *testing.T) {
+	s := newShop(t, 5)
+	s.AddToCart(s.customer, s.product.ID, 2)
+	recorder := s.CaptureSpans()
+	logs := s.CaptureLogs()
+
+	header := http.Header{
+		"Authorization": {"Bearer " + s.customer.Token},
+		"Traceparent":   {"00-" + callerTraceID + "-" + callerSpanID + "-01"},
+	}
+	if resp := s.Send(http.MethodPost, "/api/order/checkout", header, dto.RequestCreatePayment{Method: "cc"}); resp.Status != http.StatusOK {
+		t.Fatalf("POST /api/order/checkout: status %d: %s", resp.Status, resp.Body)
+	}
+	spans := recorder.Ended()
+
+	// The request continues the trace of the caller
+	server := spanNamed(t, spans, "POST /api/order/checkout")
+	if server.SpanKind() != trace.SpanKindServer || !server.Parent().IsRemote() ||
+		server.Parent().SpanID().String() != callerSpanID {
+		t.Fatalf("request span kind %v with parent %v, want a server span of the caller span", server.SpanKind(), server.Parent())
+	}
+	for _, span := range spans {
+		if span.SpanContext().TraceID().String() != callerTraceID {
+			t.Fatalf("span %s in trace %s, want %s", span.Name(), span.SpanContext().TraceID(), callerTraceID)
+		}
+	}
+
+	// Each step of the checkout has its span, down to the SQL statements
+	checkout := spanNamed(t, spans, "OrderService.Checkout")
+	assertChild(t, server, checkout)
+	assertChild(t, checkout, spanNamed(t, spans, "updateCartTotal"))
+	stock := spanNamed(t, spans, "decrementProductStockByCartItemsQuantity")
+	assertChild(t, checkout, stock)
+	assertChild(t, stock, spanNamed(t, spans, "update products"))
+	payment := spanNamed(t, spans, "PaymentService.createPayment")
+	assertChild(t, checkout, payment)
+	assertChild(t, payment, spanNamed(t, spans, "bcrypt.GenerateFromPassword"))
+	assertChild(t, payment, spanNamed(t, spans, "create payments"))
+
+	// Logs of the request name the trace
+	var traced bool
+	for _, line := range logLines(t, logs) {
+		if line["msg"] == "request" {
+			traced = line["trace_id"] == callerTraceID
+		}
+	}
+	if !traced {
+		t.Fatalf("request log has no trace_id %s:\n%s", callerTraceID, logs)
+	}
+}
+
+func TestRequestsWithoutTraceContextStartATrace(t *testing.T) {
+	app := newShop(t, 5)
+	recorder := app.CaptureSpans()
+
+	app.Get("/api/product/"+app.product.ID.String(), "")
+
+	server := spanNamed(t, recorder.Ended(), "GET /api/product/:id")
+	if server.Parent().IsValid() || !server.SpanContext().IsSampled() {
+		t.Fatalf("request span parent %v, want a sampled root span", server.Parent())
+	}
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID() != server.SpanContext().TraceID() {
+			t.Fatalf("span %s outside the trace of the request", span.Name())
+		}
+	}
+}
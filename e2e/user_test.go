@@ -0,0 +1,118 @@
+package e2e_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/apptest"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/google/uuid"
+)
+
+func TestUpdateProfileAndPassword(t *testing.T) {
+	app := apptest.New(t)
+	user := app.RegisterCustomer("Jane", "jane@example.com", "jane-password")
+
+	var updated dto.ResponseUser
+	app.Expect(http.StatusOK, http.MethodPatch, "/api/user/update", user.Token,
+		dto.RequestUpdateUser{Name: "Jane Doe"}).Data(&updated)
+	if updated.Name != "Jane Doe" {
+		t.Fatalf("name = %q, want Jane Doe", updated.Name)
+	}
+
+	app.Expect(http.StatusBadRequest, http.MethodPatch, "/api/user/change-password", user.Token,
+		dto.RequestUpdatePassword{CurrentPassword: "wrong-password", NewPassword: "new-password"})
+	app.Expect(http.StatusOK, http.MethodPatch, "/api/user/change-password", user.Token,
+		dto.RequestUpdatePassword{CurrentPassword: user.Password, NewPassword: "new-password"})
+	// Changing the password signs out every session
+	app.Expect(http.StatusUnauthorized, http.MethodGet, "/api/user/me", user.Token, nil)
+	app.Login(user.Email, "new-password")
+}
+
+func TestSessions(t *testing.T) {
+	app := apptest.New(t)
+	user := app.RegisterCustomer("Jane", "jane@example.com", "jane-password")
+	other := app.Login(user.Email, user.Password)
+	third := app.Login(user.Email, user.Password)
+
+	var sessions []dto.ResponseSession
+	app.Get("/api/user/sessions", user.Token).Data(&sessions)
+	if len(sessions) != 3 {
+		t.Fatalf("%d sessions, want 3", len(sessions))
+	}
+	var current, revoked uuid.UUID
+	for _, session := range sessions {
+		if session.Current {
+			current = session.ID
+		} else {
+			revoked = session.ID
+		}
+	}
+	if current == uuid.Nil {
+		t.Fatal("no session is marked as current")
+	}
+
+	app.Expect(http.StatusOK, http.MethodDelete, "/api/user/sessions/"+revoked.String(), user.Token, nil)
+	app.Expect(http.StatusNotFound, http.MethodDelete, "/api/user/sessions/"+revoked.String(), user.Token, nil)
+	app.Expect(http.StatusBadRequest, http.MethodDelete, "/api/user/sessions/not-a-uuid", user.Token, nil)
+	app.Get("/api/user/sessions", user.Token).Data(&sessions)
+	if len(sessions) != 2 {
+		t.Fatalf("%d sessions after signing one out, want 2", len(sessions))
+	}
+
+	app.Expect(http.StatusOK, http.MethodDelete, "/api/user/sessions", user.Token, nil)
+	for _, token := range []string{user.Token, other.Token, third.Token} {
+		app.Expect(http.StatusUnauthorized, http.MethodGet, "/api/user/me", token, nil)
+	}
+}
+
+func TestExportAndDeleteAccount(t *testing.T) {
+	s := newShop(t, 5)
+	s.AddToCart(s.customer, s.product.ID, 2)
+	s.Checkout(s.customer, "cc")
+
+	var export dto.ResponseUserExport
+	s.Get("/api/user/export", s.customer.Token).Decode(&export)
+	if export.Profile.Email != s.customer.Email {
+		t.Fatalf("exported profile = %+v, want %s", export.Profile, s.customer.Email)
+	}
+	if len(export.Orders) != 1 || len(export.Orders[0].Items) != 1 || len(export.Orders[0].Payments) != 1 {
+		t.Fatalf("exported orders = %+v, want one order with its item and payment", export.Orders)
+	}
+	if len(export.Sessions) == 0 {
+		t.Fatal("export has no sessions")
+	}
+
+	s.Expect(http.StatusUnauthorized, http.MethodDelete, "/api/user/me", s.customer.Token,
+		dto.RequestDeleteAccount{Password: "wrong-password"})
+	// Wrong passwords are throttled like failed logins, for the login too
+	throttled := s.Expect(http.StatusTooManyRequests, http.MethodDelete, "/api/user/me", s.customer.Token,
+		dto.RequestDeleteAccount{Password: s.customer.Password})
+	if throttled.Header.Get("Retry-After") == "" {
+		t.Fatal("throttled account deletion has no Retry-After header")
+	}
+	s.Expect(http.StatusTooManyRequests, http.MethodPost, "/api/auth/login", "",
+		dto.RequestLogin{Email: s.customer.Email, Password: s.customer.Password})
+
+	s.Expect(http.StatusOK, http.MethodPost, "/api/admin/lockouts/unlock", s.admin.Token, dto.RequestUnlockLogin{Email: s.customer.Email})
+	s.Expect(http.StatusOK, http.MethodDelete, "/api/user/me", s.customer.Token,
+		dto.RequestDeleteAccount{Password: s.customer.Password})
+	s.Expect(http.StatusUnauthorized, http.MethodGet, "/api/user/me", s.customer.Token, nil)
+	s.Expect(http.StatusNotFound, http.MethodPost, "/api/auth/login", "",
+		dto.RequestLogin{Email: s.customer.Email, Password: s.customer.Password})
+
+	// Orders are kept for the books, without the personal data
+	var orders dto.ResponsePaginated[dto.ResponseOrder]
+	s.Get("/api/admin/orders", s.admin.Token).Data(&orders)
+	if orders.Meta.Total != 1 {
+		t.Fatalf("%d orders after deleting the account, want 1", orders.Meta.Total)
+	}
+}
+
+func TestUserRoutesRequireAToken(t *testing.T) {
+	app := apptest.New(t)
+
+	for _, path := range []string{"/api/user/me", "/api/user/export", "/api/user/sessions", "/api/cart", "/api/order"} {
+		app.Expect(http.StatusUnauthorized, http.MethodGet, path, "", nil)
+	}
+}
@@ -0,0 +1,228 @@
+// Package apptest boots the API against a private in-memory SQLite database
+// for end-to-end tests, with helpers to call endpoints and to register and
+// sign in customers and admins.
+//
+// The API keeps its database connection, signing keys and mail sender in
+// package variables, so tests using an App must not run in parallel.
+package apptest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/router"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/logging"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/mail"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/middleware"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+)
+
+// App is the API served from router.SetupRoutes on a fresh database, behind
+// the request ID and request log middlewares
+type App struct {
+	Fiber *fiber.App
+	DB    *gorm.DB
+	// Mail keeps every email the API sent
+	Mail *mail.MemorySender
+
+	t testing.TB
+}
+
+// New boots the API on an empty, migrated in-memory database. configure
+// functions may change the authentication settings before the services are
+// built. Everything is torn down when the test ends.
+func New(t testing.TB, configure ...func(auth *config.AuthSettings)) *App {
+	t.Helper()
+
+	// Statements are logged at the debug level, only seen when a test asks for it
+	db, err := database.Open(config.DatabaseSettings{Driver: "sqlite", SQLitePath: ":memory:", LogLevel: "info"})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if _, err := database.MigrateUp(db, 0); err != nil {
+		t.Fatalf("migrate database: %v", err)
+	}
+
+	previous := database.Database
+	database.Database = database.Dbinstance{Db: db}
+	service.SetLoginAttemptStore(service.NewDatabaseLoginAttemptStore(db))
+	sender := mail.NewMemorySender()
+	mail.SetDefault(sender)
+	t.Cleanup(func() {
+		service.WaitBackground(context.Background())
+		database.Database = previous
+		mail.SetDefault(nil)
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	utils.JWTSecret = []byte("apptest-secret")
+	utils.JWTKeysDir = t.TempDir()
+	utils.JWTKeyRotationPeriod = 0
+	if err := utils.LoadSigningKeys(context.Background()); err != nil {
+		t.Fatalf("load signing keys: %v", err)
+	}
+
+	auth := config.Get().Auth
+	for _, fn := range configure {
+		fn(&auth)
+	}
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	app.Use(middleware.Tracing, middleware.RequestID, middleware.Metrics, middleware.RequestLogger)
+	router.SetupRoutes(app, router.NewHandlers(repository.NewStore(db), auth))
+
+	return &App{Fiber: app, DB: db, Mail: sender, t: t}
+}
+
+// Response is the answer of the API to a request
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+
+	t testing.TB
+}
+
+// Decode unmarshals the body into v
+func (r *Response) Decode(v interface{}) {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("decode %s: %v", r.Body, err)
+	}
+}
+
+// Data unmarshals the data of a dto.GeneralResponse body into v
+func (r *Response) Data(v interface{}) {
+	r.t.Helper()
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	r.Decode(&envelope)
+	if err := json.Unmarshal(envelope.Data, v); err != nil {
+		r.t.Fatalf("decode data of %s: %v", r.Body, err)
+	}
+}
+
+// Do sends a request, with the bearer token when not empty and body encoded
+// as JSON when not nil
+func (a *App) Do(method, path, token string, body interface{}) *Response {
+	a.t.Helper()
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	return a.Send(method, path, header, body)
+}
+
+// DoWithAPIKey sends a request like Do, authenticated with an API key
+func (a *App) DoWithAPIKey(method, path, key string, body interface{}) *Response {
+	a.t.Helper()
+	header := http.Header{}
+	header.Set(middleware.APIKeyHeader, key)
+	return a.Send(method, path, header, body)
+}
+
+// Send sends a request with the given headers, body encoded as JSON when not nil
+func (a *App) Send(method, path string, header http.Header, body interface{}) *Response {
+	a.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			a.t.Fatalf("encode body of %s %s: %v", method, path, err)
+		}
+		reader = bytes.NewReader(content)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header = header
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Hashing payment OTPs takes longer than the default test timeout
+	resp, err := a.Fiber.Test(req, -1)
+	if err != nil {
+		a.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	// Emails are sent after answering, tests look for them right away
+	service.WaitBackground(context.Background())
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		a.t.Fatalf("%s %s: read body: %v", method, path, err)
+	}
+	return &Response{Status: resp.StatusCode, Header: resp.Header, Body: content, t: a.t}
+}
+
+// Expect sends a request like Do and fails the test unless the API answers
+// with status
+func (a *App) Expect(status int, method, path, token string, body interface{}) *Response {
+	a.t.Helper()
+	return a.expect(status, a.Do(method, path, token, body), method, path)
+}
+
+func (a *App) expect(status int, resp *Response, method, path string) *Response {
+	a.t.Helper()
+	if resp.Status != status {
+		a.t.Fatalf("%s %s: status %d, want %d: %s", method, path, resp.Status, status, resp.Body)
+	}
+	return resp
+}
+
+// ExpectWithAPIKey sends a request like DoWithAPIKey and fails the test
+// unless the API answers with status
+func (a *App) ExpectWithAPIKey(status int, method, path, key string, body interface{}) *Response {
+	a.t.Helper()
+	return a.expect(status, a.DoWithAPIKey(method, path, key, body), method, path)
+}
+
+// CaptureLogs logs every message as JSON to the returned buffer, down to the
+// debug level and SQL statements included, until the test ends
+func (a *App) CaptureLogs() *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, config.LogSettings{Level: "debug", Format: "json"}))
+	a.t.Cleanup(func() {
+		slog.SetDefault(previous)
+		// slog.SetDefault sent the output of the log package to buf
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	})
+	return &buf
+}
+
+// CaptureSpans records every span ended until the test ends
+func (a *App) CaptureSpans() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	a.t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+	return recorder
+}
+
+// Get sends a GET request and expects 200 OK
+func (a *App) Get(path, token string) *Response {
+	a.t.Helper()
+	return a.Expect(http.StatusOK, http.MethodGet, path, token, nil)
+}
@@ -0,0 +1,42 @@
+package apptest
+
+import (
+	"net/http"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/google/uuid"
+)
+
+// CreateCategory creates a category through the admin API
+func (a *App) CreateCategory(admin *User, name string) dto.ResponseCategory {
+	a.t.Helper()
+	var category dto.ResponseCategory
+	a.Expect(http.StatusCreated, http.MethodPost, "/api/admin/category", admin.Token,
+		dto.RequestCategory{Name: name}).Data(&category)
+	return category
+}
+
+// CreateProduct creates a product through the admin API
+func (a *App) CreateProduct(admin *User, categoryID uuid.UUID, name string, price float64, stock int) dto.ResponseProduct {
+	a.t.Helper()
+	var product dto.ResponseProduct
+	a.Expect(http.StatusCreated, http.MethodPost, "/api/admin/product", admin.Token,
+		dto.RequestProduct{Name: name, Price: price, Stock: stock, CategoryRefer: categoryID}).Data(&product)
+	return product
+}
+
+// AddToCart adds a product to the cart of a customer
+func (a *App) AddToCart(customer *User, productID uuid.UUID, quantity int) {
+	a.t.Helper()
+	a.Expect(http.StatusOK, http.MethodPost, "/api/cart", customer.Token,
+		dto.RequestAddProductToCart{ProductID: productID, Quantity: quantity})
+}
+
+// Checkout checks out the cart of a customer
+func (a *App) Checkout(customer *User, method string) dto.ResponseCheckoutOrder {
+	a.t.Helper()
+	var checkout dto.ResponseCheckoutOrder
+	a.Expect(http.StatusOK, http.MethodPost, "/api/order/checkout", customer.Token,
+		dto.RequestCreatePayment{Method: method}).Data(&checkout)
+	return checkout
+}
@@ -0,0 +1,131 @@
+package apptest
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/totp"
+	"github.com/google/uuid"
+)
+
+var verificationLink = regexp.MustCompile(`/api/auth/verify\?token=(\S+)`)
+
+// User is an account signed in through the API
+type User struct {
+	ID       uuid.UUID
+	Name     string
+	Email    string
+	Password string
+	// Token is the access token of the last login
+	Token        string
+	RefreshToken string
+	// TOTPSecret and RecoveryCodes are set once two-factor authentication is enabled
+	TOTPSecret    string
+	RecoveryCodes []string
+}
+
+// Register registers an account and returns its ID, leaving its email unverified
+func (a *App) Register(name, email, password string) uuid.UUID {
+	a.t.Helper()
+	var registered struct {
+		ID uuid.UUID `json:"id"`
+	}
+	a.Expect(http.StatusCreated, http.MethodPost, "/api/auth/register", "",
+		dto.RequestRegister{Name: name, Email: email, Password: password}).Decode(&registered)
+	return registered.ID
+}
+
+// VerifyEmail opens the last verification link emailed to the address
+func (a *App) VerifyEmail(email string) {
+	a.t.Helper()
+	msg, ok := a.Mail.LastTo(email)
+	if !ok {
+		a.t.Fatalf("no email sent to %s", email)
+	}
+	match := verificationLink.FindStringSubmatch(msg.Body)
+	if match == nil {
+		a.t.Fatalf("no verification link in the email to %s: %s", email, msg.Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		a.t.Fatalf("verification link: %v", err)
+	}
+	a.Get("/api/auth/verify?token="+url.QueryEscape(token), "")
+}
+
+// Login logs in with a password and returns the tokens, failing the test
+// when a second factor is asked for
+func (a *App) Login(email, password string) dto.ResponseAuthToken {
+	a.t.Helper()
+	var tokens dto.ResponseAuthToken
+	a.Expect(http.StatusOK, http.MethodPost, "/api/auth/login", "",
+		dto.RequestLogin{Email: email, Password: password}).Decode(&tokens)
+	if tokens.Token == "" {
+		a.t.Fatalf("login of %s returned no token, is two-factor authentication enabled?", email)
+	}
+	return tokens
+}
+
+// RegisterCustomer registers a customer, verifies its email and logs it in
+func (a *App) RegisterCustomer(name, email, password string) *User {
+	a.t.Helper()
+	user := &User{ID: a.Register(name, email, password), Name: name, Email: email, Password: password}
+	a.VerifyEmail(email)
+	tokens := a.Login(email, password)
+	user.Token, user.RefreshToken = tokens.Token, tokens.RefreshToken
+	return user
+}
+
+// CreateAdmin creates an admin the way the create-admin command does, enables
+// two-factor authentication as the admin policy requires and logs it in with
+// a second factor
+func (a *App) CreateAdmin(name, email, password string) *User {
+	a.t.Helper()
+	admin, err := service.CreateAdminUser(name, email, password)
+	if err != nil {
+		a.t.Fatalf("create admin %s: %v", email, err)
+	}
+	user := &User{ID: admin.ID, Name: name, Email: email, Password: password}
+	user.Token = a.Login(email, password).Token
+	a.EnableTwoFactor(user)
+	a.LoginTwoFactor(user, user.RecoveryCodes[0])
+	user.RecoveryCodes = user.RecoveryCodes[1:]
+	return user
+}
+
+// EnableTwoFactor enrolls the signed in user in two-factor authentication
+func (a *App) EnableTwoFactor(user *User) {
+	a.t.Helper()
+	var setup dto.ResponseTwoFactorSetup
+	a.Expect(http.StatusOK, http.MethodPost, "/api/user/2fa/setup", user.Token, nil).Data(&setup)
+
+	code, err := totp.GenerateCode(setup.Secret, totp.Step(time.Now()))
+	if err != nil {
+		a.t.Fatalf("totp code: %v", err)
+	}
+	var recovery dto.ResponseRecoveryCodes
+	a.Expect(http.StatusOK, http.MethodPost, "/api/user/2fa/confirm", user.Token,
+		dto.RequestTwoFactorCode{Code: code}).Data(&recovery)
+	user.TOTPSecret, user.RecoveryCodes = setup.Secret, recovery.RecoveryCodes
+}
+
+// LoginTwoFactor logs in a user with two-factor authentication, code being a
+// TOTP or recovery code, and stores the new tokens in user
+func (a *App) LoginTwoFactor(user *User, code string) {
+	a.t.Helper()
+	var challenge dto.ResponseLoginChallenge
+	a.Expect(http.StatusOK, http.MethodPost, "/api/auth/login", "",
+		dto.RequestLogin{Email: user.Email, Password: user.Password}).Decode(&challenge)
+	if !challenge.TwoFactorRequired {
+		a.t.Fatalf("login of %s did not ask for a second factor", user.Email)
+	}
+
+	var tokens dto.ResponseAuthToken
+	a.Expect(http.StatusOK, http.MethodPost, "/api/auth/login/2fa", "",
+		dto.RequestLoginTwoFactor{ChallengeToken: challenge.ChallengeToken, Code: code}).Decode(&tokens)
+	user.Token, user.RefreshToken = tokens.Token, tokens.RefreshToken
+}
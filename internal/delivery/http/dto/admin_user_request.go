@@ -0,0 +1,5 @@
+package dto
+
+type RequestSuspendUser struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+)
+
+// ResponseAdminUser is a user as seen by staff managing accounts
+type ResponseAdminUser struct {
+	ResponseUser
+	SuspendedAt      *time.Time `json:"suspended_at"`
+	SuspensionReason string     `json:"suspension_reason"`
+	// PasswordResetRequired blocks password logins until the user resets their password
+	PasswordResetRequired bool `json:"password_reset_required"`
+	// AnonymizedAt is set once the account was deleted
+	AnonymizedAt *time.Time `json:"anonymized_at"`
+}
+
+func NewResponseAdminUser(u *models.User) ResponseAdminUser {
+	return ResponseAdminUser{ResponseUser: NewResponseUser(u), SuspendedAt: u.SuspendedAt, SuspensionReason: u.SuspensionReason, PasswordResetRequired: u.PasswordResetRequired, AnonymizedAt: u.AnonymizedAt}
+}
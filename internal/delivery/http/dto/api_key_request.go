@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+type RequestCreateAPIKey struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,unique,dive,oneof=catalog:write orders:read"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
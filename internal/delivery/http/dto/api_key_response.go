@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/google/uuid"
+)
+
+type ResponseAPIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	CreatedBy  uuid.UUID  `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+}
+
+// ResponseAPIKeySecret carries the plain key, only returned when it is created or rotated
+type ResponseAPIKeySecret struct {
+	ResponseAPIKey
+	Key string `json:"key"`
+}
+
+func NewResponseAPIKey(k *models.APIKey) ResponseAPIKey {
+	return ResponseAPIKey{ID: k.ID, Name: k.Name, Prefix: k.Prefix, Scopes: k.ScopeList(), CreatedAt: k.CreatedAt, CreatedBy: k.CreatedByRefer,
+		ExpiresAt: k.ExpiresAt, RevokedAt: k.RevokedAt, LastUsedAt: k.LastUsedAt, LastUsedIP: k.LastUsedIP}
+}
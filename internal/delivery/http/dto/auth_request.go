@@ -9,4 +9,31 @@ type RequestRegister struct {
 type RequestLogin struct {
 	Email    string `json:"email,omitempty"  validate:"required,email"`
 	Password string `json:"password,omitempty" validate:"required"`
-}
\ No newline at end of file
+}
+
+type RequestRefreshToken struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type RequestLogout struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type RequestEmail struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type RequestResetPassword struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+type RequestLoginTwoFactor struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	// Code is a code from the authenticator app or one of the recovery codes
+	Code string `json:"code" validate:"required"`
+}
+
+type RequestTwoFactorCode struct {
+	Code string `json:"code" validate:"required"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+type ResponseAuthToken struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+func NewResponseAuthToken(accessToken, refreshToken string, expiresIn int64) ResponseAuthToken {
+	return ResponseAuthToken{Token: accessToken, RefreshToken: refreshToken, TokenType: "Bearer", ExpiresIn: expiresIn}
+}
+
+type ResponseLoginChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int64  `json:"expires_in"`
+}
+
+type ResponseTwoFactorSetup struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+type ResponseRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
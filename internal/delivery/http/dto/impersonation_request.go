@@ -0,0 +1,6 @@
+package dto
+
+type RequestImpersonate struct {
+	// Reason is kept in the audit trail, e.g. a support ticket reference
+	Reason string `json:"reason" validate:"required,max=500"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/google/uuid"
+)
+
+type ResponseImpersonation struct {
+	// AccessToken acts as User for read requests only, there is no refresh token
+	AccessToken string       `json:"access_token"`
+	ExpiresIn   int64        `json:"expires_in"`
+	User        ResponseUser `json:"user"`
+}
+
+type ResponseImpersonationLog struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ActorID   uuid.UUID `json:"actor_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	TokenID   string    `json:"token_id"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	IP        string    `json:"ip"`
+	Blocked   bool      `json:"blocked"`
+}
+
+func NewResponseImpersonationLog(l *models.ImpersonationLog) ResponseImpersonationLog {
+	return ResponseImpersonationLog{ID: l.ID, CreatedAt: l.CreatedAt, ActorID: l.ActorRefer, UserID: l.SubjectRefer, TokenID: l.TokenID, Method: l.Method, Path: l.Path, Status: l.Status, IP: l.IP, Blocked: l.Blocked}
+}
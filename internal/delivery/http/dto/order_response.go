@@ -11,6 +11,7 @@ type ResponseOrder struct {
 	ID          uuid.UUID `json:"id"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	UserID      uuid.UUID `json:"user_id"`
 	Status      string    `json:"status"`
 	TotalAmount float64   `json:"total_amount" validate:"required,gt=0"`
 }
@@ -23,5 +24,5 @@ type ResponseCheckoutOrder struct {
 }
 
 func NewResponseOrder(p *models.Order) ResponseOrder {
-	return ResponseOrder{ID: p.ID, CreatedAt: p.CreatedAt, UpdatedAt: p.UpdatedAt, Status: p.Status, TotalAmount: p.TotalAmount}
+	return ResponseOrder{ID: p.ID, CreatedAt: p.CreatedAt, UpdatedAt: p.UpdatedAt, UserID: p.UserRefer, Status: p.Status, TotalAmount: p.TotalAmount}
 }
@@ -0,0 +1,7 @@
+package dto
+
+type RequestDeleteAccount struct {
+	Password string `json:"password" validate:"required"`
+	// Code is required when two-factor authentication is enabled
+	Code string `json:"code,omitempty"`
+}
@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/google/uuid"
+)
+
+// ResponseUserExport is everything stored about a user
+type ResponseUserExport struct {
+	ExportedAt       time.Time               `json:"exported_at"`
+	Profile          ResponseAdminUser       `json:"profile"`
+	Carts            []ResponseExportCart    `json:"carts"`
+	Orders           []ResponseExportOrder   `json:"orders"`
+	Sessions         []ResponseSession       `json:"sessions"`
+	LinkedIdentities []ResponseUserIdentity  `json:"linked_identities"`
+	SecurityEvents   []ResponseSecurityEvent `json:"security_events"`
+	// APIKeys created by the user, without their secrets
+	APIKeys []ResponseAPIKey `json:"api_keys"`
+}
+
+type ResponseExportCart struct {
+	ID          uuid.UUID          `json:"id"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	TotalAmount float64            `json:"total_amount"`
+	Items       []ResponseCartItem `json:"items"`
+}
+
+func NewResponseExportCart(c *models.Cart) ResponseExportCart {
+	return ResponseExportCart{ID: c.ID, CreatedAt: c.CreatedAt, UpdatedAt: c.UpdatedAt, TotalAmount: c.TotalAmount, Items: []ResponseCartItem{}}
+}
+
+type ResponseExportOrder struct {
+	ResponseOrder
+	Items    []ResponseExportOrderItem `json:"items"`
+	Payments []ResponseExportPayment   `json:"payments"`
+}
+
+func NewResponseExportOrder(o *models.Order) ResponseExportOrder {
+	return ResponseExportOrder{ResponseOrder: NewResponseOrder(o), Items: []ResponseExportOrderItem{}, Payments: []ResponseExportPayment{}}
+}
+
+type ResponseExportOrderItem struct {
+	ID              uuid.UUID `json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	PriceAtPurchase float64   `json:"price_at_purchase"`
+	Quantity        int       `json:"quantity"`
+}
+
+func NewResponseExportOrderItem(i *models.OrderItem) ResponseExportOrderItem {
+	return ResponseExportOrderItem{ID: i.ID, CreatedAt: i.CreatedAt, PriceAtPurchase: i.PriceAtPurchase, Quantity: i.Quantity}
+}
+
+type ResponseExportPayment struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Status    string    `json:"status"`
+	Amount    float64   `json:"amount"`
+	Method    string    `json:"method"`
+}
+
+func NewResponseExportPayment(p *models.Payment) ResponseExportPayment {
+	return ResponseExportPayment{ID: p.ID, CreatedAt: p.CreatedAt, UpdatedAt: p.UpdatedAt, Status: p.Status, Amount: p.Amount, Method: p.Method}
+}
+
+type ResponseUserIdentity struct {
+	Provider    string    `json:"provider"`
+	Subject     string    `json:"subject"`
+	Email       string    `json:"email"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+func NewResponseUserIdentity(i *models.UserIdentity) ResponseUserIdentity {
+	return ResponseUserIdentity{Provider: i.Provider, Subject: i.Subject, Email: i.Email, CreatedAt: i.CreatedAt, LastLoginAt: i.LastLoginAt}
+}
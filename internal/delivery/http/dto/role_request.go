@@ -0,0 +1,5 @@
+package dto
+
+type RequestAssignRole struct {
+	Role string `json:"role" validate:"required"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+type ResponseRole struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+func NewResponseRole(name string, permissions []string) ResponseRole {
+	return ResponseRole{Name: name, Permissions: permissions}
+}
@@ -0,0 +1,6 @@
+package dto
+
+type RequestUnlockLogin struct {
+	Email string `json:"email" validate:"required_without=IP,omitempty,email"`
+	IP    string `json:"ip" validate:"required_without=Email,omitempty,ip"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/google/uuid"
+)
+
+type ResponseSecurityEvent struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	Type      string     `json:"type"`
+	Email     string     `json:"email,omitempty"`
+	IP        string     `json:"ip,omitempty"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	Detail    string     `json:"detail"`
+}
+
+func NewResponseSecurityEvent(e *models.SecurityEvent) ResponseSecurityEvent {
+	return ResponseSecurityEvent{ID: e.ID, CreatedAt: e.CreatedAt, Type: e.Type, Email: e.Email, IP: e.IP, UserID: e.UserRefer, Detail: e.Detail}
+}
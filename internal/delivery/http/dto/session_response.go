@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/google/uuid"
+)
+
+type ResponseSession struct {
+	ID           uuid.UUID `json:"id"`
+	UserAgent    string    `json:"user_agent"`
+	IP           string    `json:"ip"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastActiveAt time.Time `json:"last_active_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	// Current marks the session of the token used for the request
+	Current bool `json:"current"`
+}
+
+func NewResponseSession(s *models.Session, current bool) ResponseSession {
+	return ResponseSession{ID: s.ID, UserAgent: s.UserAgent, IP: s.IP, CreatedAt: s.CreatedAt, LastActiveAt: s.LastActiveAt, ExpiresAt: s.ExpiresAt, Current: current}
+}
@@ -14,8 +14,13 @@ type ResponseUser struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	Role      string    `json:"role"`
+	// Permissions are the permissions granted by Role
+	Permissions []string   `json:"permissions"`
+	VerifiedAt  *time.Time `json:"verified_at"`
+	// TwoFactorEnabled tells whether logins require a TOTP or recovery code
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
 }
 
 func NewResponseUser(u *models.User) ResponseUser {
-	return ResponseUser{ID: u.ID, Name: u.Name, Email: u.Email, CreatedAt: u.CreatedAt, UpdatedAt: u.UpdatedAt, Role: u.Role}
+	return ResponseUser{ID: u.ID, Name: u.Name, Email: u.Email, CreatedAt: u.CreatedAt, UpdatedAt: u.UpdatedAt, Role: u.Role, Permissions: models.RolePermissions[u.Role], VerifiedAt: u.VerifiedAt, TwoFactorEnabled: u.TwoFactorEnabledAt != nil}
 }
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetUsers godoc
+// @Summary List users
+// @Description List users, newest first. Requires the user:read permission.
+// @Tags admin
+// @Produce json
+// @Param search query string false "Part of the name or email"
+// @Param role query string false "Only users with this role"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(20)
+// @Success 200 {object} dto.ResponsePaginated[dto.ResponseAdminUser] "Users"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/users [get]
+// @Security BearerAuth
+func GetUsers(c *fiber.Ctx) error {
+	role := c.Query("role")
+	if role != "" && !models.IsValidRole(role) {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid role", service.ErrInvalidRole.Error()))
+	}
+
+	query, page, limit := utils.GetPaginatedQuery(&models.User{}, c.Query("page", "1"), c.Query("limit", "20"))
+	query = service.FilterUsers(query, c.Query("search"), role)
+
+	var totalData int64
+	query.Count(&totalData)
+
+	var users []models.User
+	if err := service.GetUsers(&users, query); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Error getting users", err.Error()))
+	}
+
+	userDTOs := make([]dto.ResponseAdminUser, 0, len(users))
+	for i := range users {
+		userDTOs = append(userDTOs, dto.NewResponseAdminUser(&users[i]))
+	}
+
+	paginatedResponse := dto.ResponsePaginated[dto.ResponseAdminUser]{
+		Meta: dto.PaginatedMeta{
+			Limit: limit,
+			Total: int(totalData),
+			Page:  page,
+		},
+		List: userDTOs,
+	}
+	return c.JSON(dto.NewSuccessResponse(paginatedResponse, "Users retrieved successfully"))
+}
+
+// GetUser godoc
+// @Summary Get a user
+// @Description Get a user's account details. Requires the user:read permission.
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.ResponseAdminUser "User"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 404 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/users/{id} [get]
+// @Security BearerAuth
+func (h *UserHandler) GetUser(c *fiber.Ctx) error {
+	userID, err := utils.CheckUUID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid user ID", err.Error()))
+	}
+
+	user, err := h.users.GetUser(c.UserContext(), *userID)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("User not found", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Error retrieving user", err.Error()))
+	}
+
+	return c.JSON(dto.NewSuccessResponse(dto.NewResponseAdminUser(user), "User retrieved successfully"))
+}
+
+// SuspendUser godoc
+// @Summary Suspend a user
+// @Description Block a user from logging in and sign them out everywhere. Requires the user:manage permission; staff accounts can only be suspended by admins.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param suspendDTO body dto.RequestSuspendUser false "Reason for the suspension"
+// @Success 200 {object} dto.ResponseAdminUser "Suspended user"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 403 {object} dto.GeneralResponse "Error Message"
+// @Failure 404 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/users/{id}/suspend [post]
+// @Security BearerAuth
+func SuspendUser(c *fiber.Ctx) error {
+	userID, err := utils.CheckUUID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid user ID", err.Error()))
+	}
+
+	var suspendDTO dto.RequestSuspendUser
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&suspendDTO); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+		}
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(suspendDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+	}
+
+	actorID, actorRole := actor(c)
+	user, err := service.SuspendUser(*userID, actorID, actorRole, suspendDTO.Reason)
+	if err != nil {
+		return manageUserError(c, "Could not suspend user", err)
+	}
+
+	return c.JSON(dto.NewSuccessResponse(dto.NewResponseAdminUser(user), "User suspended successfully"))
+}
+
+// ReactivateUser godoc
+// @Summary Reactivate a user
+// @Description Lift a user's suspension. Requires the user:manage permission; staff accounts can only be reactivated by admins.
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.ResponseAdminUser "Reactivated user"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 403 {object} dto.GeneralResponse "Error Message"
+// @Failure 404 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/users/{id}/reactivate [post]
+// @Security BearerAuth
+func ReactivateUser(c *fiber.Ctx) error {
+	userID, err := utils.CheckUUID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid user ID", err.Error()))
+	}
+
+	actorID, actorRole := actor(c)
+	user, err := service.ReactivateUser(*userID, actorID, actorRole)
+	if err != nil {
+		return manageUserError(c, "Could not reactivate user", err)
+	}
+
+	return c.JSON(dto.NewSuccessResponse(dto.NewResponseAdminUser(user), "User reactivated successfully"))
+}
+
+// ForcePasswordReset godoc
+// @Summary Force a password reset
+// @Description Sign a user out everywhere and email them a password reset link. Password logins are refused until the password is reset. Requires the user:manage permission; staff accounts can only be reset by admins.
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.ResponseAdminUser "User"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 403 {object} dto.GeneralResponse "Error Message"
+// @Failure 404 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/users/{id}/force-password-reset [post]
+// @Security BearerAuth
+func ForcePasswordReset(c *fiber.Ctx) error {
+	userID, err := utils.CheckUUID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid user ID", err.Error()))
+	}
+
+	actorID, actorRole := actor(c)
+	user, err := service.ForcePasswordReset(*userID, actorID, actorRole)
+	if err != nil {
+		if user != nil {
+			// The reset is enforced but the user has to request a new link themselves
+			return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Password reset required but the email could not be sent", err.Error()))
+		}
+		return manageUserError(c, "Could not force a password reset", err)
+	}
+
+	return c.JSON(dto.NewSuccessResponse(dto.NewResponseAdminUser(user), "Password reset email sent"))
+}
+
+// actor returns the ID and role of the staff member making the request
+func actor(c *fiber.Ctx) (uuid.UUID, string) {
+	actorID, _ := c.Locals("userID").(uuid.UUID)
+	actorRole, _ := c.Locals("role").(string)
+	return actorID, actorRole
+}
+
+func manageUserError(c *fiber.Ctx, message string, err error) error {
+	switch err {
+	case service.ErrCannotManageSelf:
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(message, err.Error()))
+	case service.ErrStaffAccount:
+		return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse(message, err.Error()))
+	case service.ErrUserNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("User not found", err.Error()))
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse(message, err.Error()))
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create a scoped API key for an integration. The key is only returned in this response.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param apiKeyDTO body dto.RequestCreateAPIKey true "Key name, scopes and optional expiry"
+// @Success 201 {object} dto.ResponseAPIKeySecret "Created API key"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 500 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/api-keys [post]
+// @Security BearerAuth
+func CreateAPIKey(c *fiber.Ctx) error {
+	adminID := c.Locals("userID").(uuid.UUID)
+
+	var apiKeyDTO dto.RequestCreateAPIKey
+	if err := c.BodyParser(&apiKeyDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(apiKeyDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+	}
+
+	apiKey, key, err := service.CreateAPIKey(apiKeyDTO.Name, apiKeyDTO.Scopes, apiKeyDTO.ExpiresAt, adminID)
+	if err != nil {
+		if err == service.ErrInvalidAPIKeyTTL {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not create API key", err.Error()))
+	}
+
+	response := dto.ResponseAPIKeySecret{ResponseAPIKey: dto.NewResponseAPIKey(apiKey), Key: key}
+	return c.Status(fiber.StatusCreated).JSON(dto.NewSuccessResponse(response, "API key created, store it now as it will not be shown again"))
+}
+
+// GetAPIKeys godoc
+// @Summary List API keys
+// @Description List API keys, including revoked ones, newest first
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(20)
+// @Success 200 {array} dto.ResponseAPIKey "API keys"
+// @Failure 500 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/api-keys [get]
+// @Security BearerAuth
+func GetAPIKeys(c *fiber.Ctx) error {
+	query, page, limit := utils.GetPaginatedQuery(&models.APIKey{}, c.Query("page", "1"), c.Query("limit", "20"))
+
+	var totalData int64
+	query.Count(&totalData)
+
+	var apiKeys []models.APIKey
+	if err := service.GetAPIKeys(&apiKeys, query); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Error getting API keys", err.Error()))
+	}
+
+	apiKeyDTOs := make([]dto.ResponseAPIKey, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		apiKeyDTOs = append(apiKeyDTOs, dto.NewResponseAPIKey(&apiKey))
+	}
+
+	paginatedResponse := dto.ResponsePaginated[dto.ResponseAPIKey]{
+		Meta: dto.PaginatedMeta{
+			Limit: limit,
+			Total: int(totalData),
+			Page:  page,
+		},
+		List: apiKeyDTOs,
+	}
+	return c.JSON(dto.NewSuccessResponse(paginatedResponse, "API keys retrieved successfully"))
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate an API key
+// @Description Replace the secret of an API key, keeping its name and scopes. The previous key stops working immediately.
+// @Tags admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} dto.ResponseAPIKeySecret "Rotated API key"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 404 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/api-keys/{id}/rotate [post]
+// @Security BearerAuth
+func RotateAPIKey(c *fiber.Ctx) error {
+	apiKeyID, err := utils.CheckUUID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid API key ID", err.Error()))
+	}
+
+	apiKey, key, err := service.RotateAPIKey(*apiKeyID)
+	if err != nil {
+		if err == service.ErrAPIKeyNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("API key not found", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not rotate API key", err.Error()))
+	}
+
+	response := dto.ResponseAPIKeySecret{ResponseAPIKey: dto.NewResponseAPIKey(apiKey), Key: key}
+	return c.JSON(dto.NewSuccessResponse(response, "API key rotated, store it now as it will not be shown again"))
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Permanently disable an API key
+// @Tags admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} dto.GeneralResponse "Success Message"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 404 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/api-keys/{id} [delete]
+// @Security BearerAuth
+func RevokeAPIKey(c *fiber.Ctx) error {
+	apiKeyID, err := utils.CheckUUID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid API key ID", err.Error()))
+	}
+
+	if err := service.RevokeAPIKey(*apiKeyID); err != nil {
+		if err == service.ErrAPIKeyNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("API key not found", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not revoke API key", err.Error()))
+	}
+
+	return c.JSON(dto.NewSuccessResponse(nil, "API key revoked successfully"))
+}
@@ -1,13 +1,28 @@
 package handlers
 
 import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
 	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
+// AuthHandler serves the endpoints that sign users up, in and out
+type AuthHandler struct {
+	auth *service.AuthService
+}
+
+func NewAuthHandler(auth *service.AuthService) *AuthHandler {
+	return &AuthHandler{auth: auth}
+}
+
 // Register godoc
 // @Summary Register a new user
 // @Description Register a new user with a name, email, and password
@@ -19,7 +34,7 @@ import (
 // @Failure 400 {object} dto.GeneralResponse "Error Message"
 // @Failure 500 {object} dto.GeneralResponse "Error Message"
 // @Router /auth/register [post]
-func Register(c *fiber.Ctx) error {
+func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var registerDTO dto.RequestRegister
 
 	// Parse and validate the request body
@@ -33,7 +48,7 @@ func Register(c *fiber.Ctx) error {
 	}
 
 	// Use the service layer to register the user
-	user, err := service.RegisterUser(registerDTO.Name, registerDTO.Email, registerDTO.Password)
+	user, err := h.auth.Register(c.UserContext(), registerDTO.Name, registerDTO.Email, registerDTO.Password)
 	if err != nil {
 		if err == service.ErrEmailExists {
 			return c.Status(400).JSON(fiber.Map{"error": "Email already in use"})
@@ -46,16 +61,18 @@ func Register(c *fiber.Ctx) error {
 
 // Login godoc
 // @Summary Log in a user
-// @Description Authenticate a user with email and password
+// @Description Authenticate a user with email and password. Users with two-factor authentication receive a challenge token to complete at /auth/login/2fa instead of tokens.
 // @Tags auth
 // @Accept  json
 // @Produce  json
 // @Param loginDTO body dto.RequestLogin true "User login data"
-// @Success 200 {object} dto.GeneralResponse "Success Message"
+// @Success 200 {object} dto.ResponseAuthToken "Access and refresh tokens, or dto.ResponseLoginChallenge"
 // @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 403 {object} dto.GeneralResponse "Account suspended or password reset required"
 // @Failure 404 {object} dto.GeneralResponse "Error Message"
+// @Failure 429 {object} dto.GeneralResponse "Too many failed attempts, see the Retry-After header"
 // @Router /auth/login [post]
-func Login(c *fiber.Ctx) error {
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var loginDTO dto.RequestLogin
 
 	// Parse the request body into the struct
@@ -69,21 +86,268 @@ func Login(c *fiber.Ctx) error {
 	}
 
 	// Use the service layer to authenticate the user
-	user, err := service.AuthenticateUser(loginDTO.Email, loginDTO.Password)
+	user, err := h.auth.Authenticate(c.UserContext(), loginDTO.Email, loginDTO.Password, c.IP())
 	if err != nil {
-		if err == service.ErrUserNotFound {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			setRetryAfter(c, throttled.RetryAfter)
+			return c.Status(429).JSON(fiber.Map{"error": "Too many failed login attempts"})
+		} else if err == service.ErrUserNotFound {
 			return c.Status(404).JSON(fiber.Map{"error": "User not found"})
 		} else if err == service.ErrInvalidPassword {
 			return c.Status(400).JSON(fiber.Map{"error": "Invalid password"})
+		} else if err == service.ErrAccountSuspended || err == service.ErrPasswordResetRequired {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
 		}
 		return c.Status(500).JSON(fiber.Map{"error": "Could not authenticate user"})
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID, user.Role)
+	// Users with two-factor authentication must complete a second step
+	if user.TwoFactorEnabledAt != nil {
+		challenge, err := service.CreateLoginChallenge(user)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Could not generate token"})
+		}
+		return c.JSON(dto.ResponseLoginChallenge{TwoFactorRequired: true, ChallengeToken: challenge, ExpiresIn: int64(service.LoginChallengeTTL.Seconds())})
+	}
+
+	// Generate access and refresh tokens
+	tokens, err := h.auth.IssueTokens(c.UserContext(), user, false, clientInfo(c))
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Could not generate token"})
 	}
 
-	return c.JSON(fiber.Map{"token": token})
+	return c.JSON(dto.NewResponseAuthToken(tokens.AccessToken, tokens.RefreshToken, tokens.ExpiresIn))
+}
+
+// LoginTwoFactor godoc
+// @Summary Complete a two-step login
+// @Description Exchange the challenge token returned by /auth/login and a TOTP or recovery code for access and refresh tokens
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param loginDTO body dto.RequestLoginTwoFactor true "Challenge token and code"
+// @Success 200 {object} dto.ResponseAuthToken "Access and refresh tokens"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 401 {object} dto.GeneralResponse "Error Message"
+// @Failure 403 {object} dto.GeneralResponse "Error Message"
+// @Failure 429 {object} dto.GeneralResponse "Too many failed attempts, see the Retry-After header"
+// @Router /auth/login/2fa [post]
+func (h *AuthHandler) LoginTwoFactor(c *fiber.Ctx) error {
+	var loginDTO dto.RequestLoginTwoFactor
+	if err := c.BodyParser(&loginDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(loginDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+	}
+
+	user, err := service.CompleteLoginChallenge(loginDTO.ChallengeToken, loginDTO.Code, c.IP())
+	if err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			setRetryAfter(c, throttled.RetryAfter)
+			return c.Status(fiber.StatusTooManyRequests).JSON(dto.NewErrorResponse("Too many failed login attempts", err.Error()))
+		}
+		if err == service.ErrInvalidLoginChallenge || err == service.ErrInvalidTwoFactorCode {
+			return c.Status(fiber.StatusUnauthorized).JSON(dto.NewErrorResponse("Invalid authentication code", err.Error()))
+		}
+		if err == service.ErrAccountSuspended {
+			return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse("Could not authenticate user", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not authenticate user", err.Error()))
+	}
+
+	tokens, err := h.auth.IssueTokens(c.UserContext(), user, true, clientInfo(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not generate token", err.Error()))
+	}
+
+	return c.JSON(dto.NewResponseAuthToken(tokens.AccessToken, tokens.RefreshToken, tokens.ExpiresIn))
+}
+
+// RefreshToken godoc
+// @Summary Refresh an access token
+// @Description Exchange a refresh token for a new access token and a new refresh token. The presented refresh token is revoked.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param refreshDTO body dto.RequestRefreshToken true "Refresh token"
+// @Success 200 {object} dto.ResponseAuthToken "New token pair"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 401 {object} dto.GeneralResponse "Error Message"
+// @Failure 403 {object} dto.GeneralResponse "Error Message"
+// @Router /auth/refresh [post]
+func RefreshToken(c *fiber.Ctx) error {
+	var refreshDTO dto.RequestRefreshToken
+	if err := c.BodyParser(&refreshDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(refreshDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+	}
+
+	tokens, err := service.RotateRefreshToken(refreshDTO.RefreshToken, clientInfo(c))
+	if err != nil {
+		if err == service.ErrInvalidRefreshToken {
+			return c.Status(fiber.StatusUnauthorized).JSON(dto.NewErrorResponse("Invalid refresh token", err.Error()))
+		}
+		if err == service.ErrAccountSuspended {
+			return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse("Could not refresh token", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not refresh token", err.Error()))
+	}
+
+	return c.JSON(dto.NewResponseAuthToken(tokens.AccessToken, tokens.RefreshToken, tokens.ExpiresIn))
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description End the current session, revoking its access token and the refresh tokens issued with it
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param logoutDTO body dto.RequestLogout false "Refresh token to revoke"
+// @Success 200 {object} dto.GeneralResponse "Success Message"
+// @Failure 401 {object} dto.GeneralResponse "Error Message"
+// @Failure 500 {object} dto.GeneralResponse "Error Message"
+// @Router /auth/logout [post]
+// @Security BearerAuth
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+	claims := c.Locals("claims").(*utils.Claims)
+
+	var logoutDTO dto.RequestLogout
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&logoutDTO); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+		}
+	}
+
+	if err := h.auth.Logout(c.UserContext(), userID, claims, logoutDTO.RefreshToken); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not log out", err.Error()))
+	}
+
+	return c.JSON(dto.NewSuccessResponse(nil, "Logged out successfully"))
+}
+
+// VerifyEmail godoc
+// @Summary Verify an email address
+// @Description Confirm the ownership of an email address with the token sent after registration
+// @Tags auth
+// @Produce  json
+// @Param token query string true "Verification token"
+// @Success 200 {object} dto.GeneralResponse "Success Message"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Router /auth/verify [get]
+func VerifyEmail(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Missing verification token", nil))
+	}
+
+	user, err := service.VerifyEmail(token)
+	if err != nil {
+		if err == service.ErrInvalidVerificationToken {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid verification token", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not verify email", err.Error()))
+	}
+
+	return c.JSON(dto.NewSuccessResponse(dto.NewResponseUser(user), "Email verified successfully"))
+}
+
+// ResendVerification godoc
+// @Summary Resend the verification email
+// @Description Send a new verification link. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param resendDTO body dto.RequestEmail true "Email address"
+// @Success 200 {object} dto.GeneralResponse "Success Message"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Router /auth/verify/resend [post]
+func ResendVerification(c *fiber.Ctx) error {
+	var resendDTO dto.RequestEmail
+	if err := c.BodyParser(&resendDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(resendDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+	}
+
+	service.ResendVerificationEmail(resendDTO.Email)
+	return c.JSON(dto.NewSuccessResponse(nil, "If the email is registered and not yet verified, a verification link has been sent"))
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Email a one-time password reset link. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param forgotDTO body dto.RequestEmail true "Email address"
+// @Success 200 {object} dto.GeneralResponse "Success Message"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Router /auth/forgot-password [post]
+func ForgotPassword(c *fiber.Ctx) error {
+	var forgotDTO dto.RequestEmail
+	if err := c.BodyParser(&forgotDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(forgotDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+	}
+
+	service.RequestPasswordReset(forgotDTO.Email)
+	return c.JSON(dto.NewSuccessResponse(nil, "If the email is registered, a password reset link has been sent"))
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password with a reset token. Every session of the user is signed out.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param resetDTO body dto.RequestResetPassword true "Reset token and new password"
+// @Success 200 {object} dto.GeneralResponse "Success Message"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Router /auth/reset-password [post]
+func ResetPassword(c *fiber.Ctx) error {
+	var resetDTO dto.RequestResetPassword
+	if err := c.BodyParser(&resetDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(resetDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+	}
+
+	if err := service.ResetPassword(resetDTO.Token, resetDTO.NewPassword); err != nil {
+		if err == service.ErrInvalidResetToken {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid reset token", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Failed to reset password", err.Error()))
+	}
+
+	return c.JSON(dto.NewSuccessResponse(nil, "Password reset successfully"))
+}
+
+// setRetryAfter tells a throttled client how many seconds to wait before retrying
+func setRetryAfter(c *fiber.Ctx, retryAfter time.Duration) {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+}
+
+// clientInfo describes the device making the request, recorded on its session
+func clientInfo(c *fiber.Ctx) service.ClientInfo {
+	return service.ClientInfo{UserAgent: c.Get(fiber.HeaderUserAgent), IP: c.IP()}
 }
@@ -1,17 +1,26 @@
 package handlers
 
 import (
-	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"errors"
+
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
-	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
 	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
+// CartHandler serves the shopping cart endpoints
+type CartHandler struct {
+	carts *service.CartService
+}
+
+func NewCartHandler(carts *service.CartService) *CartHandler {
+	return &CartHandler{carts: carts}
+}
+
 // AddToCart godoc
 // @Summary Add a product to the shopping cart
 // @Description Add a product to the customer's shopping cart
@@ -25,89 +34,31 @@ import (
 // @Failure 409 {object} dto.GeneralResponse "Insufficient stock"
 // @Router /cart [post]
 // @Security BearerAuth
-func AddToCart(c *fiber.Ctx) error {
-	// Begin a new transaction
-	tx := database.Database.Db.Begin()
-	if tx.Error != nil {
-		response := dto.NewErrorResponse("Error starting transaction", tx.Error.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(response)
-	}
-
+func (h *CartHandler) AddToCart(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uuid.UUID)
 
 	var addToCartRequest dto.RequestAddProductToCart
 	if err := c.BodyParser(&addToCartRequest); err != nil {
-		tx.Rollback()
 		response := dto.NewErrorResponse("Invalid request", err.Error())
 		return c.Status(fiber.StatusBadRequest).JSON(response)
 	}
 
 	validate := validator.New()
 	if err := validate.Struct(&addToCartRequest); err != nil {
-		tx.Rollback()
 		response := dto.NewErrorResponse("Validation error", err.Error())
 		return c.Status(fiber.StatusBadRequest).JSON(response)
 	}
 
-	var product models.Product
-	if err := tx.First(&product, "id = ?", addToCartRequest.ProductID).Error; err != nil {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Product not found", err.Error())
-		return c.Status(fiber.StatusNotFound).JSON(response)
-	}
-
-	// Check product stock
-	if product.Stock < addToCartRequest.Quantity {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Insufficient stock", "Not enough stock available for this product")
-		return c.Status(fiber.StatusConflict).JSON(response)
-	}
-
-	var cart models.Cart
-	if err := service.FindOrCreateCartByUserId(&cart, &userID); err != nil {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Error When Finding Cart", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(response)
-	}
-
-	var cartItem models.CartItem
-	if err := tx.Where("cart_refer = ? AND product_refer = ?", cart.ID, product.ID).First(&cartItem).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			cartItem = models.CartItem{
-				CartRefer:    cart.ID,
-				ProductRefer: product.ID,
-				Quantity:     addToCartRequest.Quantity,
-			}
-			if err := tx.Create(&cartItem).Error; err != nil {
-				tx.Rollback()
-				response := dto.NewErrorResponse("Error adding product to cart", err.Error())
-				return c.Status(fiber.StatusInternalServerError).JSON(response)
-			}
-		} else {
-			tx.Rollback()
-			response := dto.NewErrorResponse("Error finding cart item", err.Error())
-			return c.Status(fiber.StatusInternalServerError).JSON(response)
-		}
-	} else {
-		cartItem.Quantity += addToCartRequest.Quantity
-		if err := tx.Save(&cartItem).Error; err != nil {
-			tx.Rollback()
-			response := dto.NewErrorResponse("Error updating cart item", err.Error())
-			return c.Status(fiber.StatusInternalServerError).JSON(response)
+	if err := h.carts.AddToCart(c.UserContext(), userID, addToCartRequest.ProductID, addToCartRequest.Quantity); err != nil {
+		switch {
+		case errors.Is(err, service.ErrProductNotFound):
+			response := dto.NewErrorResponse("Product not found", err.Error())
+			return c.Status(fiber.StatusNotFound).JSON(response)
+		case errors.Is(err, service.ErrInsufficientStock):
+			response := dto.NewErrorResponse("Insufficient stock", "Not enough stock available for this product")
+			return c.Status(fiber.StatusConflict).JSON(response)
 		}
-	}
-
-	// Update the total cart amount
-	if err := service.UpdateCartTotalTransaction(&cart, tx); err != nil {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Error updating cart total", err.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(response)
-	}
-
-	// Commit the transaction if everything went well
-	if err := tx.Commit().Error; err != nil {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Error committing transaction", err.Error())
+		response := dto.NewErrorResponse("Error adding product to cart", err.Error())
 		return c.Status(fiber.StatusInternalServerError).JSON(response)
 	}
 
@@ -128,25 +79,15 @@ func AddToCart(c *fiber.Ctx) error {
 // @Failure 401 {object} dto.GeneralResponse "Unauthorized"
 // @Router /cart [get]
 // @Security BearerAuth
-func GetCartItems(c *fiber.Ctx) error {
+func (h *CartHandler) GetCartItems(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uuid.UUID)
-	// get cart by user id
-	var cart models.Cart
-	if err := service.FindOrCreateCartByUserId(&cart, &userID); err != nil {
-		response := dto.NewErrorResponse("Error When Finding Cart", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(response)
-	}
-	// get cart items by cart id
-	query, page, limit := utils.GetPaginatedQuery(&models.CartItem{}, c.Query("page", "1"), c.Query("limit", "10"))
-	var totalData int64
-
-	query.Count(&totalData)
+	page, limit := utils.ParsePagination(c.Query("page", "1"), c.Query("limit", "10"))
 
-	// query cartItems
-	var cartItems []models.CartItem
-	if err := service.GetCartItemsListByCartId(&cartItems, &cart.ID, query); err != nil {
+	// get the cart of the user with a page of its items
+	cart, cartItems, totalData, err := h.carts.GetCart(c.UserContext(), userID, repository.Page{Number: page, Limit: limit})
+	if err != nil {
 		response := dto.NewErrorResponse("Error getting cart items", err.Error())
-		return c.Status(fiber.StatusNotFound).JSON(response)
+		return c.Status(fiber.StatusInternalServerError).JSON(response)
 	}
 	var cartItemDtos []dto.ResponseCartItem
 	for _, cartItem := range cartItems {
@@ -164,7 +105,7 @@ func GetCartItems(c *fiber.Ctx) error {
 		List: cartItemDtos,
 	}
 
-	responseCart := dto.NewResponseCart(&cart)
+	responseCart := dto.NewResponseCart(cart)
 	responseCart.CartItems = paginatedCartItems
 	response := dto.NewSuccessResponse(responseCart, "Cart Items Retrieved")
 	return c.Status(200).JSON(response)
@@ -181,65 +122,29 @@ func GetCartItems(c *fiber.Ctx) error {
 // @Failure 401 {object} dto.GeneralResponse "Unauthorized"
 // @Router /cart/{id} [delete]
 // @Security BearerAuth
-func RemoveCartItems(c *fiber.Ctx) error {
-	// Begin a new transaction
-	tx := database.Database.Db.Begin()
-	if tx.Error != nil {
-		response := dto.NewErrorResponse("Error starting transaction", tx.Error.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(response)
-	}
-
+func (h *CartHandler) RemoveCartItems(c *fiber.Ctx) error {
 	// Get user ID from token
 	userID := c.Locals("userID").(uuid.UUID)
 	// Get cart items ID from params
-	cartItemID := c.Params("id")
-
-	// Find the cart item by its ID
-	var cartItem models.CartItem
-	if err := tx.Preload("Cart").First(&cartItem, "id = ?", cartItemID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+	cartItemID, err := utils.CheckUUID(c.Params("id"))
+	if err != nil {
+		response := dto.NewErrorResponse("Cart item not found", err.Error())
+		return c.Status(fiber.StatusNotFound).JSON(response)
+	}
 
+	if err := h.carts.RemoveCartItem(c.UserContext(), userID, *cartItemID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrCartItemNotFound):
 			response := dto.NewErrorResponse("Cart item not found", err.Error())
 			return c.Status(fiber.StatusNotFound).JSON(response)
-		}
-		response := dto.NewErrorResponse("Error finding cart item", err.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(response)
-	}
-
-	// Find the cart associated with this cart item and userID
-	var cart models.Cart
-	if err := tx.First(&cart, "id = ? AND user_refer = ?", cartItem.CartRefer, userID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-
+		case errors.Is(err, service.ErrCartNotFound):
 			response := dto.NewErrorResponse("Cart Not Found", err.Error())
 			return c.Status(fiber.StatusNotFound).JSON(response)
 		}
-
-		response := dto.NewErrorResponse("Error finding cart", err.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(response)
-	}
-
-	// Delete the cart item
-	if err := tx.Delete(&cartItem).Error; err != nil {
-		tx.Rollback()
 		response := dto.NewErrorResponse("Error removing cart item", err.Error())
 		return c.Status(fiber.StatusInternalServerError).JSON(response)
 	}
 
-	// Update the total cart amount
-	if err := service.UpdateCartTotalTransaction(&cart, tx); err != nil {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Error updating cart total", err.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(response)
-	}
-
-	// Commit the transaction if everything went well
-	if err := tx.Commit().Error; err != nil {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Error committing transaction", err.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(response)
-	}
-
 	// Return a success response
 	response := dto.NewSuccessResponse(nil, "Cart item removed successfully")
 	return c.Status(fiber.StatusOK).JSON(response)
@@ -256,19 +161,20 @@ func RemoveCartItems(c *fiber.Ctx) error {
 // @Failure 401 {object} dto.GeneralResponse "Unauthorized"
 // @Router /cart/{id} [get]
 // @Security BearerAuth
-func GetCartItemById(c *fiber.Ctx) error {
-
-	productID := c.Params("id")
+func (h *CartHandler) GetCartItemById(c *fiber.Ctx) error {
+	cartItemID, err := utils.CheckUUID(c.Params("id"))
+	if err != nil {
+		response := dto.NewErrorResponse("Product not found", err.Error())
+		return c.Status(fiber.StatusNotFound).JSON(response)
+	}
 
-	// Fetch the user from the database
-	var cartItem models.CartItem
-	if err := database.Database.Db.Preload("Product").First(&cartItem, "id = ?", productID).Error; err != nil {
+	cartItem, err := h.carts.GetCartItem(c.UserContext(), *cartItemID)
+	if err != nil {
 		response := dto.NewErrorResponse("Product not found", err.Error())
 		return c.Status(fiber.StatusNotFound).JSON(response)
 	}
 	// Return the user details
-	response := dto.NewSuccessResponse(dto.NewResponseCartItem(&cartItem), "cart item data retrieved successfully")
+	response := dto.NewSuccessResponse(dto.NewResponseCartItem(cartItem), "cart item data retrieved successfully")
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
-// Updated the function to use the transaction
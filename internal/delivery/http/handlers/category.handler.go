@@ -1,9 +1,8 @@
 package handlers
 
 import (
-	"github.com/arsyaputraa/go-synapsis-challenge/database"
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
-	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
 	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
 	"github.com/go-playground/validator/v10"
@@ -11,6 +10,15 @@ import (
 	"github.com/jinzhu/copier"
 )
 
+// CategoryHandler serves the category endpoints
+type CategoryHandler struct {
+	catalog *service.CatalogService
+}
+
+func NewCategoryHandler(catalog *service.CatalogService) *CategoryHandler {
+	return &CategoryHandler{catalog: catalog}
+}
+
 // Get Categories godoc
 // @Summary Get Categories
 // @Description Get a list of Categories
@@ -21,14 +29,11 @@ import (
 // @Success 200 {array} dto.ResponseProduct "Categories data retrieved successfully"
 // @Failure 401 {object} dto.GeneralResponse "Unauthorized"
 // @Router /category [get]
-func GetCategories(c *fiber.Ctx) error {
-	query, page, limit := utils.GetPaginatedQuery(&models.Category{}, c.Query("page", "1"), c.Query("limit", "20"))
+func (h *CategoryHandler) GetCategories(c *fiber.Ctx) error {
+	page, limit := utils.ParsePagination(c.Query("page", "1"), c.Query("limit", "20"))
 
-	var totalData int64
-
-	query.Count(&totalData)
-	var categories []models.Category
-	if err := service.GetCategories(&categories, query); err != nil {
+	categories, totalData, err := h.catalog.GetCategories(c.UserContext(), repository.Page{Number: page, Limit: limit})
+	if err != nil {
 		response := dto.NewErrorResponse("Error getting products", err.Error())
 		return c.Status(fiber.StatusNotFound).JSON(response)
 	}
@@ -63,7 +68,8 @@ func GetCategories(c *fiber.Ctx) error {
 // @Failure 400 {object} dto.GeneralResponse "Bad request"
 // @Router /admin/category [post]
 // @Security BearerAuth
-func AddCategory(c *fiber.Ctx) error {
+// @Security ApiKeyAuth
+func (h *CategoryHandler) AddCategory(c *fiber.Ctx) error {
 	var requestCategory dto.RequestCategory
 	if err := c.BodyParser(&requestCategory); err != nil {
 		response := dto.NewErrorResponse("Invalid request", err.Error())
@@ -79,7 +85,7 @@ func AddCategory(c *fiber.Ctx) error {
 
 	newCategory := requestCategory.ToModel()
 
-	if err := service.CreateCategory(&newCategory, database.Database.Db); err != nil {
+	if err := h.catalog.CreateCategory(c.UserContext(), &newCategory); err != nil {
 		response := dto.NewErrorResponse("Error creating product", err.Error())
 		return c.Status(fiber.StatusInternalServerError).JSON(response)
 	}
@@ -90,7 +96,7 @@ func AddCategory(c *fiber.Ctx) error {
 
 // UpdateCategory godoc
 // @Summary Update a Category
-// @Description Update a Category's. Only accessible by users with the 'admin' role.
+// @Description Update a Category's. Requires the category:update permission.
 // @Tags admin
 // @Accept json
 // @Produce json
@@ -102,9 +108,9 @@ func AddCategory(c *fiber.Ctx) error {
 // @Failure 403 {object} dto.GeneralResponse "Forbidden. Only admin can access this endpoint."
 // @Router /admin/category/{id} [patch]
 // @Security BearerAuth
-func UpdateCategory(c *fiber.Ctx) error {
+// @Security ApiKeyAuth
+func (h *CategoryHandler) UpdateCategory(c *fiber.Ctx) error {
 	categoryID := c.Params("id")
-	db := database.Database.Db
 	categoryUUID, err := utils.CheckUUID(categoryID)
 	if err != nil {
 		response := dto.NewErrorResponse("Invalid Category ID", err.Error())
@@ -123,29 +129,29 @@ func UpdateCategory(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response)
 	}
 
-	var category models.Category
-	if err := service.GetCategoryByID(&category, *categoryUUID, db); err != nil {
+	category, err := h.catalog.GetCategoryByID(c.UserContext(), *categoryUUID)
+	if err != nil {
 		response := dto.NewErrorResponse("Category not found", err.Error())
 		return c.Status(fiber.StatusNotFound).JSON(response)
 	}
 
-	if err := copier.CopyWithOption(&category, &updateCategory, copier.Option{IgnoreEmpty: true}); err != nil {
+	if err := copier.CopyWithOption(category, &updateCategory, copier.Option{IgnoreEmpty: true}); err != nil {
 		response := dto.NewErrorResponse("Error updating category", err.Error())
 		return c.Status(fiber.StatusInternalServerError).JSON(response)
 	}
 
-	if err := service.UpdateCategory(&category, db); err != nil {
+	if err := h.catalog.UpdateCategory(c.UserContext(), category); err != nil {
 		response := dto.NewErrorResponse("Error updating product", err.Error())
 		return c.Status(fiber.StatusInternalServerError).JSON(response)
 	}
 
-	response := dto.NewSuccessResponse(dto.NewResponseCategory(&category), "Category updated successfully")
+	response := dto.NewSuccessResponse(dto.NewResponseCategory(category), "Category updated successfully")
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
 // DeleteCategory godoc
 // @Summary Delete a Category
-// @Description Delete a Category by its ID. Only accessible by users with the 'admin' role.
+// @Description Delete a Category by its ID. Requires the category:delete permission.
 // @Tags admin
 // @Produce json
 // @Param id path string true "Category ID"
@@ -153,22 +159,22 @@ func UpdateCategory(c *fiber.Ctx) error {
 // @Failure 404 {object} dto.GeneralResponse "Category not found"
 // @Router /admin/category/{id} [delete]
 // @Security BearerAuth
-func DeleteCategory(c *fiber.Ctx) error {
+// @Security ApiKeyAuth
+func (h *CategoryHandler) DeleteCategory(c *fiber.Ctx) error {
 	categoryID := c.Params("id")
-	db := database.Database.Db
 	categoryUUID, err := utils.CheckUUID(categoryID)
 	if err != nil {
 		response := dto.NewErrorResponse("Invalid Category ID", err.Error())
 		return c.Status(fiber.StatusBadRequest).JSON(response)
 	}
 
-	var category models.Category
-	if err := service.GetCategoryByID(&category, *categoryUUID, db); err != nil {
+	category, err := h.catalog.GetCategoryByID(c.UserContext(), *categoryUUID)
+	if err != nil {
 		response := dto.NewErrorResponse("Category not found", err.Error())
 		return c.Status(fiber.StatusNotFound).JSON(response)
 	}
 
-	if err := service.DeleteCategory(&category, db); err != nil {
+	if err := h.catalog.DeleteCategory(c.UserContext(), category); err != nil {
 		response := dto.NewErrorResponse("Error deleting category", err.Error())
 		return c.Status(fiber.StatusInternalServerError).JSON(response)
 	}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/gofiber/fiber/v2"
+)
+
+// readinessTimeout bounds the checks of a readiness probe, so a hanging
+// database fails the probe instead of stalling it
+const readinessTimeout = 2 * time.Second
+
+// GetHealth godoc
+// @Summary Liveness probe
+// @Description Answers as long as the process serves requests. Served outside the /api base path at /healthz.
+// @Tags monitoring
+// @Produce json
+// @Success 200 {object} dto.GeneralResponse "Alive"
+// @Router /healthz [get]
+func GetHealth(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(dto.NewSuccessResponse(nil, "ok"))
+}
+
+// GetReadiness godoc
+// @Summary Readiness probe
+// @Description Answers 200 when the database is reachable and every migration is applied, and 503 otherwise. Served outside the /api base path at /readyz.
+// @Tags monitoring
+// @Produce json
+// @Success 200 {object} dto.GeneralResponse "Ready"
+// @Failure 503 {object} dto.GeneralResponse "Database unreachable or migrations pending"
+// @Router /readyz [get]
+func GetReadiness(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), readinessTimeout)
+	defer cancel()
+
+	if err := database.Ready(ctx); err != nil {
+		slog.WarnContext(c.UserContext(), "not ready", "error", err)
+		// The cause stays in the logs, it names hosts and ports
+		reason := database.ErrUnreachable.Error()
+		if errors.Is(err, database.ErrMigrationsPending) {
+			reason = database.ErrMigrationsPending.Error()
+		}
+		return c.Status(fiber.StatusServiceUnavailable).JSON(dto.NewErrorResponse("Not ready", reason))
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.NewSuccessResponse(nil, "ready"))
+}
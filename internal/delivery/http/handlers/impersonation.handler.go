@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ImpersonateUser godoc
+// @Summary Impersonate a customer
+// @Description Get a short-lived access token acting as a customer, to see what they see. The token only allows read requests and every request made with it is recorded. Requires the user:impersonate permission.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param impersonateDTO body dto.RequestImpersonate true "Reason for the impersonation"
+// @Success 200 {object} dto.ResponseImpersonation "Impersonation token"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 403 {object} dto.GeneralResponse "Error Message"
+// @Failure 404 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/users/{id}/impersonate [post]
+// @Security BearerAuth
+func ImpersonateUser(c *fiber.Ctx) error {
+	actorID := c.Locals("userID").(uuid.UUID)
+
+	userID, err := utils.CheckUUID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid user ID", err.Error()))
+	}
+
+	var impersonateDTO dto.RequestImpersonate
+	if err := c.BodyParser(&impersonateDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(impersonateDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+	}
+
+	token, user, err := service.StartImpersonation(*userID, actorID, impersonateDTO.Reason, c.IP())
+	if err != nil {
+		switch err {
+		case service.ErrCannotManageSelf:
+			return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Could not impersonate user", err.Error()))
+		case service.ErrCannotImpersonate:
+			return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse("Could not impersonate user", err.Error()))
+		case service.ErrUserNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("User not found", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not impersonate user", err.Error()))
+	}
+
+	return c.JSON(dto.NewSuccessResponse(dto.ResponseImpersonation{
+		AccessToken: token,
+		ExpiresIn:   int64(service.ImpersonationTokenTTL.Seconds()),
+		User:        dto.NewResponseUser(user),
+	}, "Impersonation started"))
+}
+
+// GetImpersonationLogs godoc
+// @Summary Impersonation audit trail
+// @Description List the requests made while impersonating, newest first. Requires the audit:read permission.
+// @Tags admin
+// @Produce json
+// @Param actor_id query string false "Only requests by this staff member"
+// @Param user_id query string false "Only requests acting as this user"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(20)
+// @Success 200 {object} dto.ResponsePaginated[dto.ResponseImpersonationLog] "Impersonated requests"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/impersonation-logs [get]
+// @Security BearerAuth
+func GetImpersonationLogs(c *fiber.Ctx) error {
+	query, page, limit := utils.GetPaginatedQuery(&models.ImpersonationLog{}, c.Query("page", "1"), c.Query("limit", "20"))
+
+	if actorID := c.Query("actor_id"); actorID != "" {
+		actorUUID, err := utils.CheckUUID(actorID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid actor ID", err.Error()))
+		}
+		query = query.Where("actor_refer = ?", actorUUID)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		userUUID, err := utils.CheckUUID(userID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid user ID", err.Error()))
+		}
+		query = query.Where("subject_refer = ?", userUUID)
+	}
+
+	var totalData int64
+	query.Count(&totalData)
+
+	var logs []models.ImpersonationLog
+	if err := service.GetImpersonationLogs(&logs, query); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Error getting impersonation logs", err.Error()))
+	}
+
+	logDTOs := make([]dto.ResponseImpersonationLog, 0, len(logs))
+	for i := range logs {
+		logDTOs = append(logDTOs, dto.NewResponseImpersonationLog(&logs[i]))
+	}
+
+	paginatedResponse := dto.ResponsePaginated[dto.ResponseImpersonationLog]{
+		Meta: dto.PaginatedMeta{
+			Limit: limit,
+			Total: int(totalData),
+			Page:  page,
+		},
+		List: logDTOs,
+	}
+	return c.JSON(dto.NewSuccessResponse(paginatedResponse, "Impersonation logs retrieved successfully"))
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetJWKS godoc
+// @Summary Access token verification keys
+// @Description Public keys access tokens are signed with, as a JSON Web Key Set. Tokens name their key in the kid header. Served outside the /api base path at /.well-known/jwks.json.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} keyset.JSONWebKeySet "JSON Web Key Set"
+// @Router /.well-known/jwks.json [get]
+func GetJWKS(c *fiber.Ctx) error {
+	// Verifiers may cache the keys briefly, new keys are published before they sign
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(utils.JWKS())
+}
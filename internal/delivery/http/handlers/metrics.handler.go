@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/metrics"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+var metricsHandler = adaptor.HTTPHandler(metrics.Handler())
+
+// GetMetrics godoc
+// @Summary Prometheus metrics
+// @Description Request counts and latencies by route, SQL statement durations, connection pool stats and business counters (carts created, orders checked out, payments paid or failed, stock-outs) in the Prometheus text format. Served outside the /api base path at /metrics, keep it private to the monitoring network.
+// @Tags monitoring
+// @Produce plain
+// @Success 200 {string} string "Metrics in the Prometheus text format"
+// @Router /metrics [get]
+func GetMetrics(c *fiber.Ctx) error {
+	return metricsHandler(c)
+}
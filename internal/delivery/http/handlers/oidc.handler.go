@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/oidc"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/auth/oidc"
+)
+
+// OIDCLogin godoc
+// @Summary Log in with an identity provider
+// @Description Redirect to the OpenID Connect provider to sign in. The provider redirects back to /auth/oidc/{provider}/callback.
+// @Tags auth
+// @Param provider path string true "Provider name as configured in OIDC_PROVIDERS"
+// @Success 302 "Redirect to the identity provider"
+// @Failure 404 {object} dto.GeneralResponse "Error Message"
+// @Router /auth/oidc/{provider}/login [get]
+func OIDCLogin(c *fiber.Ctx) error {
+	login, err := service.BeginOIDCLogin(c.UserContext(), c.Params("provider"))
+	if err != nil {
+		if err == oidc.ErrUnknownProvider {
+			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("Unknown identity provider", err.Error()))
+		}
+		return c.Status(fiber.StatusBadGateway).JSON(dto.NewErrorResponse("Identity provider unavailable", err.Error()))
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    login.StateToken,
+		Path:     oidcCookiePath,
+		Expires:  time.Now().Add(service.OIDCStateTTL),
+		HTTPOnly: true,
+		Secure:   c.Secure(),
+		// Lax is required for the cookie to be sent on the redirect back from the provider
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return c.Redirect(login.AuthURL, fiber.StatusFound)
+}
+
+// OIDCCallback godoc
+// @Summary Complete a login with an identity provider
+// @Description Called by the OpenID Connect provider after signing in. Returns the same tokens as /auth/login, or a two-factor challenge. New users are registered as customers.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} dto.ResponseAuthToken "Access and refresh tokens, or dto.ResponseLoginChallenge"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 401 {object} dto.GeneralResponse "Error Message"
+// @Failure 403 {object} dto.GeneralResponse "Error Message"
+// @Failure 409 {object} dto.GeneralResponse "Error Message"
+// @Router /auth/oidc/{provider}/callback [get]
+func (h *AuthHandler) OIDCCallback(c *fiber.Ctx) error {
+	stateToken := c.Cookies(oidcStateCookie)
+	// The state is single use
+	c.Cookie(&fiber.Cookie{Name: oidcStateCookie, Path: oidcCookiePath, Expires: time.Unix(0, 0), HTTPOnly: true, Secure: c.Secure()})
+
+	if providerError := c.Query("error"); providerError != "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.NewErrorResponse("Login was not completed at the identity provider", providerError+": "+c.Query("error_description")))
+	}
+	if c.Query("code") == "" || c.Query("state") == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Missing code or state", nil))
+	}
+
+	user, err := service.CompleteOIDCLogin(c.UserContext(), c.Params("provider"), stateToken, c.Query("state"), c.Query("code"))
+	if err != nil {
+		switch err {
+		case oidc.ErrUnknownProvider:
+			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("Unknown identity provider", err.Error()))
+		case service.ErrInvalidOIDCState, service.ErrOIDCEmailRequired:
+			return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Could not log in", err.Error()))
+		case service.ErrOIDCLoginFailed:
+			return c.Status(fiber.StatusUnauthorized).JSON(dto.NewErrorResponse("Could not log in", err.Error()))
+		case service.ErrAccountSuspended:
+			return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse("Could not log in", err.Error()))
+		case service.ErrOIDCAccountExists:
+			return c.Status(fiber.StatusConflict).JSON(dto.NewErrorResponse("Could not log in", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not log in", err.Error()))
+	}
+
+	// Users with two-factor authentication must complete a second step, as with a password login
+	if user.TwoFactorEnabledAt != nil {
+		challenge, err := service.CreateLoginChallenge(user)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not generate token", err.Error()))
+		}
+		return c.JSON(dto.ResponseLoginChallenge{TwoFactorRequired: true, ChallengeToken: challenge, ExpiresIn: int64(service.LoginChallengeTTL.Seconds())})
+	}
+
+	tokens, err := h.auth.IssueTokens(c.UserContext(), user, false, clientInfo(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not generate token", err.Error()))
+	}
+
+	return c.JSON(dto.NewResponseAuthToken(tokens.AccessToken, tokens.RefreshToken, tokens.ExpiresIn))
+}
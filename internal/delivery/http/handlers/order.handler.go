@@ -1,15 +1,26 @@
 package handlers
 
 import (
-	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"errors"
+
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
-	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
+// OrderHandler serves the order endpoints
+type OrderHandler struct {
+	orders *service.OrderService
+}
+
+func NewOrderHandler(orders *service.OrderService) *OrderHandler {
+	return &OrderHandler{orders: orders}
+}
+
 // Checkout godoc
 // @Summary Checkout cart and create an order
 // @Description Checkout cart and create an order
@@ -19,106 +30,52 @@ import (
 // @Param payment body dto.RequestCreatePayment true "Payment details"
 // @Success 200 {object} dto.GeneralResponse "Order created successfully"
 // @Failure 400 {object} dto.GeneralResponse "Bad request"
+// @Failure 403 {object} dto.GeneralResponse "Email not verified"
 // @Failure 404 {object} dto.GeneralResponse "Cart not found or empty"
 // @Failure 409 {object} dto.GeneralResponse "Insufficient stock"
 // @Router /order/checkout [post]
 // @Security BearerAuth
-func CheckoutOrder(c *fiber.Ctx) error {
-	// Begin a new transaction
-	tx := database.Database.Db.Begin()
-	if tx.Error != nil {
-		response := dto.NewErrorResponse("Error starting transaction", tx.Error.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(response)
-	}
-
+func (h *OrderHandler) CheckoutOrder(c *fiber.Ctx) error {
 	// Get user ID from token
 	userID := c.Locals("userID").(uuid.UUID)
 
-	// Find the user's cart
-	var cart models.Cart
-	if err := service.FindCartByUserId(&cart, &userID, tx); err != nil {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Cart not found", "No cart found for this user.")
-		return c.Status(fiber.StatusNotFound).JSON(response)
-	}
-
-	// query cartItems
-	var cartItems []models.CartItem
-	if err := service.GetCartItemsListByCartId(&cartItems, &cart.ID, tx); err != nil {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Error getting cart items", err.Error())
-		return c.Status(fiber.StatusNotFound).JSON(response)
-	}
-	// Check if the cart is empty
-	if len(cartItems) == 0 || cartItems == nil {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Cart is empty", "Cannot checkout an empty cart.")
-		return c.Status(fiber.StatusBadRequest).JSON(response)
-	}
-
-	// update cart total
-	if err := service.UpdateCartTotalTransaction(&cart, tx); err != nil {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Error updating cart total", err.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(response)
-	}
-
-	// Create a new order
-
-	var order models.Order
-	if err := service.CreateOrder(&order, &userID, cart.TotalAmount, tx); err != nil {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Error creating order", err.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(response)
-	}
-
-	// Process each cart item
-	for _, cartItem := range cartItems {
-		if err := service.DecrementProductStockByCartItemsQuantity(cartItem, order.ID, tx); err != nil {
-			tx.Rollback()
-			response := dto.NewErrorResponse("Error processing cart items", err.Error())
-			return c.Status(fiber.StatusInternalServerError).JSON(response)
-		}
-	}
-
-	validate := validator.New()
-	// Create payment record
 	var paymentRequest dto.RequestCreatePayment
-	var otp string
-	var payment models.Payment
 	if err := c.BodyParser(&paymentRequest); err != nil {
-		tx.Rollback()
 		response := dto.NewErrorResponse("Invalid payment request", err.Error())
 		return c.Status(fiber.StatusBadRequest).JSON(response)
 	}
 
+	validate := validator.New()
 	if err := validate.Struct(&paymentRequest); err != nil {
-		tx.Rollback()
 		response := dto.NewErrorResponse("Validation error", err.Error())
 		return c.Status(fiber.StatusBadRequest).JSON(response)
 	}
 
-	if err := service.CreatePayment(&order, &payment, &paymentRequest, &otp, tx); err != nil {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Error creating payment", err.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(response)
-	}
-
-	if err := service.ClearCart(cart.ID, tx); err != nil {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Error clearing cart", err.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(response)
-	}
-
-	// Commit the transaction if everything went well
-	if err := tx.Commit().Error; err != nil {
-		tx.Rollback()
-		response := dto.NewErrorResponse("Error committing transaction", err.Error())
+	checkout, err := h.orders.Checkout(c.UserContext(), userID, paymentRequest.Method)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrEmailNotVerified):
+			response := dto.NewErrorResponse("Email not verified", "Verify your email address before checking out.")
+			return c.Status(fiber.StatusForbidden).JSON(response)
+		case errors.Is(err, service.ErrUserNotFound):
+			response := dto.NewErrorResponse("User not found", err.Error())
+			return c.Status(fiber.StatusNotFound).JSON(response)
+		case errors.Is(err, service.ErrCartNotFound):
+			response := dto.NewErrorResponse("Cart not found", "No cart found for this user.")
+			return c.Status(fiber.StatusNotFound).JSON(response)
+		case errors.Is(err, service.ErrCartEmpty):
+			response := dto.NewErrorResponse("Cart is empty", "Cannot checkout an empty cart.")
+			return c.Status(fiber.StatusBadRequest).JSON(response)
+		case errors.Is(err, service.ErrInsufficientStock):
+			response := dto.NewErrorResponse("Insufficient stock", err.Error())
+			return c.Status(fiber.StatusConflict).JSON(response)
+		}
+		response := dto.NewErrorResponse("Error creating order", err.Error())
 		return c.Status(fiber.StatusInternalServerError).JSON(response)
 	}
 
 	// Return a success response
-	response := dto.NewSuccessResponse(dto.ResponseCheckoutOrder{ID: order.ID, Otp: otp, TotalAmount: order.TotalAmount, PaymentID: payment.ID}, "Order created successfully")
+	response := dto.NewSuccessResponse(dto.ResponseCheckoutOrder{ID: checkout.Order.ID, Otp: checkout.Otp, TotalAmount: checkout.Order.TotalAmount, PaymentID: checkout.Payment.ID}, "Order created successfully")
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
@@ -132,11 +89,11 @@ func CheckoutOrder(c *fiber.Ctx) error {
 // @Failure 401 {object} dto.GeneralResponse "Unauthorized"
 // @Router /order [get]
 // @Security BearerAuth
-func GetUserOrders(c *fiber.Ctx) error {
+func (h *OrderHandler) GetUserOrders(c *fiber.Ctx) error {
 	// Get user ID from the request context
 	userID := c.Locals("userID").(uuid.UUID)
 	// Fetch orders using the service layer
-	orders, err := service.GetUserOrders(userID)
+	orders, err := h.orders.GetUserOrders(c.UserContext(), userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Error retrieving orders", err.Error()))
 	}
@@ -146,3 +103,39 @@ func GetUserOrders(c *fiber.Ctx) error {
 	}
 	return c.JSON(dto.NewSuccessResponse(orderResponses, "Orders retrieved successfully"))
 }
+
+// GetAllOrders godoc
+// @Summary Get all orders
+// @Description Get the orders of every user, newest first
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(20)
+// @Success 200 {array} dto.ResponseOrder "List of orders"
+// @Failure 500 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/orders [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *OrderHandler) GetAllOrders(c *fiber.Ctx) error {
+	page, limit := utils.ParsePagination(c.Query("page", "1"), c.Query("limit", "20"))
+
+	orders, totalData, err := h.orders.GetOrders(c.UserContext(), repository.Page{Number: page, Limit: limit})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Error retrieving orders", err.Error()))
+	}
+
+	orderResponses := make([]dto.ResponseOrder, 0, len(orders))
+	for _, order := range orders {
+		orderResponses = append(orderResponses, dto.NewResponseOrder(&order))
+	}
+
+	paginatedResponse := dto.ResponsePaginated[dto.ResponseOrder]{
+		Meta: dto.PaginatedMeta{
+			Limit: limit,
+			Total: int(totalData),
+			Page:  page,
+		},
+		List: orderResponses,
+	}
+	return c.JSON(dto.NewSuccessResponse(paginatedResponse, "Orders retrieved successfully"))
+}
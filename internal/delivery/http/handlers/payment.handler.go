@@ -1,14 +1,23 @@
 package handlers
 
 import (
-	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"errors"
+
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
-	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
 	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 )
 
+// PaymentHandler serves the payment gateway webhook
+type PaymentHandler struct {
+	payments *service.PaymentService
+}
+
+func NewPaymentHandler(payments *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{payments: payments}
+}
+
 // PaymentWebhook godoc
 // @Summary Mocking Webhook for payment gateway to update payment and order status
 // @Description Webhook endpoint to mock update payment status upon completion/failed payment
@@ -23,7 +32,7 @@ import (
 // @Failure 404 {object} dto.GeneralResponse "Payment not found"
 // @Failure 500 {object} dto.GeneralResponse "Internal server error"
 // @Router /webhook/payment [get]
-func PaymentWebhook(c *fiber.Ctx) error {
+func (h *PaymentHandler) PaymentWebhook(c *fiber.Ctx) error {
 	paymentID := c.Query("paymentId")
 	paymentStatus := c.Query("status")
 	paymentOtp := c.Query("otp")
@@ -35,44 +44,16 @@ func PaymentWebhook(c *fiber.Ctx) error {
 
 	var webhookRequest = dto.RequestPaymentWebhook{PaymentID: *paymentUUID, Status: paymentStatus, Otp: paymentOtp}
 
-	// Start a transaction
-	tx := database.Database.Db.Begin()
-	if tx.Error != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Error starting transaction", tx.Error.Error()))
-	}
-
-	payment, err := service.AuthenticatePayment(webhookRequest.PaymentID, webhookRequest.Otp, tx)
-	if err != nil {
-		tx.Rollback()
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.NewErrorResponse("Unauthorized Payment", err.Error()))
-	}
-	// Update payment status
-	payment.Status = webhookRequest.Status
-	if err := service.SavePayment(payment, tx); err != nil {
-		tx.Rollback()
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Error updating payment status", err.Error()))
-	}
-
-	// If payment is successful, update the order status
-
-	if webhookRequest.Status == "paid" {
-		order, err := service.GetOrderById(payment.OrderRefer, tx)
-		if err != nil {
-			tx.Rollback()
+	if err := h.payments.UpdatePaymentStatus(c.UserContext(), webhookRequest.PaymentID, webhookRequest.Status, webhookRequest.Otp); err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("Payment not found", err.Error()))
+		case errors.Is(err, service.ErrInvalidPaymentCredential):
+			return c.Status(fiber.StatusUnauthorized).JSON(dto.NewErrorResponse("Unauthorized Payment", err.Error()))
+		case errors.Is(err, service.ErrOrderNotFound):
 			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("Order not found", err.Error()))
 		}
-		order.Status = string(models.PaidOrder)
-
-		if err := service.SaveOrder(order, tx); err != nil {
-			tx.Rollback()
-			return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Error updating order status", err.Error()))
-		}
-	}
-
-	// Commit the transaction
-	if err := tx.Commit().Error; err != nil {
-		tx.Rollback()
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Error committing transaction", err.Error()))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Error updating payment status", err.Error()))
 	}
 
 	// Return a success response
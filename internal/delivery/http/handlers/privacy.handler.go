@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ExportUserData godoc
+// @Summary Export my data
+// @Description Download a JSON archive of everything stored about the authenticated user: profile, carts, orders with their payments, sessions, linked identities, security events and API keys created.
+// @Tags user
+// @Produce json
+// @Success 200 {object} dto.ResponseUserExport "JSON archive, sent as an attachment"
+// @Failure 401 {object} dto.GeneralResponse "Error Message"
+// @Failure 404 {object} dto.GeneralResponse "Error Message"
+// @Router /user/export [get]
+// @Security BearerAuth
+func ExportUserData(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	data, err := service.ExportUserData(userID)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("User not found", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not export data", err.Error()))
+	}
+
+	c.Attachment("user-data-" + userID.String() + ".json")
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(newUserExport(data))
+}
+
+// DeleteMe godoc
+// @Summary Delete my account
+// @Description Delete the authenticated user's account. Personal data is erased and the user is signed out everywhere; orders and payments are kept anonymised for accounting. Requires the password and, with two-factor authentication enabled, a TOTP or recovery code. Wrong credentials are throttled like failed logins.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param deleteDTO body dto.RequestDeleteAccount true "Password and second factor"
+// @Success 200 {object} dto.GeneralResponse "Account deleted"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 401 {object} dto.GeneralResponse "Error Message"
+// @Failure 409 {object} dto.GeneralResponse "Error Message"
+// @Failure 429 {object} dto.GeneralResponse "Too many failed attempts, see the Retry-After header"
+// @Router /user/me [delete]
+// @Security BearerAuth
+func DeleteMe(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	var deleteDTO dto.RequestDeleteAccount
+	if err := c.BodyParser(&deleteDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(deleteDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+	}
+
+	if err := service.DeleteOwnAccount(userID, deleteDTO.Password, deleteDTO.Code, c.IP()); err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			setRetryAfter(c, throttled.RetryAfter)
+			return c.Status(fiber.StatusTooManyRequests).JSON(dto.NewErrorResponse("Too many failed attempts", err.Error()))
+		}
+		switch err {
+		case service.ErrInvalidCurrentPassword, service.ErrInvalidTwoFactorCode:
+			return c.Status(fiber.StatusUnauthorized).JSON(dto.NewErrorResponse("Could not delete account", err.Error()))
+		case service.ErrLastAdmin, service.ErrAccountDeleted:
+			return c.Status(fiber.StatusConflict).JSON(dto.NewErrorResponse("Could not delete account", err.Error()))
+		case service.ErrUserNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("User not found", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not delete account", err.Error()))
+	}
+
+	return c.JSON(dto.NewSuccessResponse(nil, "Account deleted successfully"))
+}
+
+// DeleteUser godoc
+// @Summary Delete a user's account
+// @Description Delete a user's account on their behalf, e.g. for a privacy request received by support. Personal data is erased and orders and payments are kept anonymised. Requires the user:manage permission; staff accounts can only be deleted by admins.
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.ResponseAdminUser "Anonymised user"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 403 {object} dto.GeneralResponse "Error Message"
+// @Failure 404 {object} dto.GeneralResponse "Error Message"
+// @Failure 409 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/users/{id} [delete]
+// @Security BearerAuth
+func DeleteUser(c *fiber.Ctx) error {
+	userID, err := utils.CheckUUID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid user ID", err.Error()))
+	}
+
+	actorID, actorRole := actor(c)
+	user, err := service.DeleteUserAccount(*userID, actorID, actorRole)
+	if err != nil {
+		if err == service.ErrLastAdmin || err == service.ErrAccountDeleted {
+			return c.Status(fiber.StatusConflict).JSON(dto.NewErrorResponse("Could not delete user", err.Error()))
+		}
+		return manageUserError(c, "Could not delete user", err)
+	}
+
+	return c.JSON(dto.NewSuccessResponse(dto.NewResponseAdminUser(user), "User deleted successfully"))
+}
+
+// newUserExport arranges the stored data of a user for the export archive
+func newUserExport(data *service.UserData) dto.ResponseUserExport {
+	export := dto.ResponseUserExport{
+		ExportedAt:       time.Now(),
+		Profile:          dto.NewResponseAdminUser(&data.User),
+		Carts:            make([]dto.ResponseExportCart, 0, len(data.Carts)),
+		Orders:           make([]dto.ResponseExportOrder, 0, len(data.Orders)),
+		Sessions:         make([]dto.ResponseSession, 0, len(data.Sessions)),
+		LinkedIdentities: make([]dto.ResponseUserIdentity, 0, len(data.Identities)),
+		SecurityEvents:   make([]dto.ResponseSecurityEvent, 0, len(data.SecurityEvents)),
+		APIKeys:          make([]dto.ResponseAPIKey, 0, len(data.APIKeys)),
+	}
+
+	carts := map[uuid.UUID]int{}
+	for i := range data.Carts {
+		carts[data.Carts[i].ID] = len(export.Carts)
+		export.Carts = append(export.Carts, dto.NewResponseExportCart(&data.Carts[i]))
+	}
+	for i := range data.CartItems {
+		if index, ok := carts[data.CartItems[i].CartRefer]; ok {
+			export.Carts[index].Items = append(export.Carts[index].Items, dto.NewResponseCartItem(&data.CartItems[i]))
+		}
+	}
+
+	orders := map[uuid.UUID]int{}
+	for i := range data.Orders {
+		orders[data.Orders[i].ID] = len(export.Orders)
+		export.Orders = append(export.Orders, dto.NewResponseExportOrder(&data.Orders[i]))
+	}
+	for i := range data.OrderItems {
+		if index, ok := orders[data.OrderItems[i].OrderRefer]; ok {
+			export.Orders[index].Items = append(export.Orders[index].Items, dto.NewResponseExportOrderItem(&data.OrderItems[i]))
+		}
+	}
+	for i := range data.Payments {
+		if index, ok := orders[data.Payments[i].OrderRefer]; ok {
+			export.Orders[index].Payments = append(export.Orders[index].Payments, dto.NewResponseExportPayment(&data.Payments[i]))
+		}
+	}
+
+	for i := range data.Sessions {
+		export.Sessions = append(export.Sessions, dto.NewResponseSession(&data.Sessions[i], false))
+	}
+	for i := range data.Identities {
+		export.LinkedIdentities = append(export.LinkedIdentities, dto.NewResponseUserIdentity(&data.Identities[i]))
+	}
+	for i := range data.SecurityEvents {
+		export.SecurityEvents = append(export.SecurityEvents, dto.NewResponseSecurityEvent(&data.SecurityEvents[i]))
+	}
+	for i := range data.APIKeys {
+		export.APIKeys = append(export.APIKeys, dto.NewResponseAPIKey(&data.APIKeys[i]))
+	}
+	return export
+}
@@ -1,9 +1,8 @@
 package handlers
 
 import (
-	"github.com/arsyaputraa/go-synapsis-challenge/database"
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
-	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
 	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
 	"github.com/go-playground/validator/v10"
@@ -12,6 +11,15 @@ import (
 	"github.com/jinzhu/copier"
 )
 
+// ProductHandler serves the product endpoints
+type ProductHandler struct {
+	catalog *service.CatalogService
+}
+
+func NewProductHandler(catalog *service.CatalogService) *ProductHandler {
+	return &ProductHandler{catalog: catalog}
+}
+
 // Get Products By Category godoc
 // @Summary Get Product By Category
 // @Description Get a list of products by category or return all products if no category is specified
@@ -24,27 +32,22 @@ import (
 // @Failure 404 {object} dto.GeneralResponse "User not found"
 // @Failure 401 {object} dto.GeneralResponse "Unauthorized"
 // @Router /product [get]
-func GetProductList(c *fiber.Ctx) error {
-	// Fetch the user from the database
+func (h *ProductHandler) GetProductList(c *fiber.Ctx) error {
 	categoryID := c.Query("category_id")
+	page, limit := utils.ParsePagination(c.Query("page", "1"), c.Query("limit", "10"))
 
-	query, page, limit := utils.GetPaginatedQuery(&models.Product{}, c.Query("page", "1"), c.Query("limit", "10"))
-	query = query.Preload("Category")
-
-	var totalData int64
-
+	var categoryUUID *uuid.UUID
 	if categoryID != "" {
-		categoryUUID, err := utils.CheckUUID(categoryID)
+		var err error
+		categoryUUID, err = utils.CheckUUID(categoryID)
 		if err != nil {
 			response := dto.NewErrorResponse("Invalid Category ID", err.Error())
 			return c.Status(fiber.StatusBadRequest).JSON(response)
 		}
-		query = query.Where("category_refer = ?", categoryUUID)
 	}
 
-	query.Count(&totalData)
-	var products []models.Product
-	if err := service.GetProducts(&products, query); err != nil {
+	products, totalData, err := h.catalog.GetProducts(c.UserContext(), categoryUUID, repository.Page{Number: page, Limit: limit})
+	if err != nil {
 		response := dto.NewErrorResponse("Error getting products", err.Error())
 		return c.Status(fiber.StatusNotFound).JSON(response)
 	}
@@ -78,7 +81,7 @@ func GetProductList(c *fiber.Ctx) error {
 // @Failure 404 {object} dto.GeneralResponse "User not found"
 // @Failure 401 {object} dto.GeneralResponse "Unauthorized"
 // @Router /product/{id} [get]
-func GetProduct(c *fiber.Ctx) error {
+func (h *ProductHandler) GetProduct(c *fiber.Ctx) error {
 	productIDStr := c.Params("id")
 	productID, err := uuid.Parse(productIDStr)
 	if err != nil {
@@ -86,15 +89,14 @@ func GetProduct(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response)
 	}
 
-	var product models.Product
-
-	if err := service.GetProductByID(&product, productID, database.Database.Db); err != nil {
+	product, err := h.catalog.GetProductByID(c.UserContext(), productID)
+	if err != nil {
 		response := dto.NewErrorResponse("Product not found", err.Error())
 		return c.Status(fiber.StatusNotFound).JSON(response)
 	}
 
 	// Return the user details
-	response := dto.NewSuccessResponse(dto.NewResponseProduct(&product), "User data retrieved successfully")
+	response := dto.NewSuccessResponse(dto.NewResponseProduct(product), "User data retrieved successfully")
 	return c.JSON(response)
 
 }
@@ -110,7 +112,8 @@ func GetProduct(c *fiber.Ctx) error {
 // @Failure 400 {object} dto.GeneralResponse "Bad request"
 // @Router /admin/product [post]
 // @Security BearerAuth
-func AddProduct(c *fiber.Ctx) error {
+// @Security ApiKeyAuth
+func (h *ProductHandler) AddProduct(c *fiber.Ctx) error {
 	// Parse request body
 	var requestProduct dto.RequestProduct
 	if err := c.BodyParser(&requestProduct); err != nil {
@@ -136,7 +139,7 @@ func AddProduct(c *fiber.Ctx) error {
 	newProduct := requestProduct.ToModel()
 
 	// Save the new product to the database
-	if err := service.CreateProduct(&newProduct, database.Database.Db); err != nil {
+	if err := h.catalog.CreateProduct(c.UserContext(), &newProduct); err != nil {
 		response := dto.NewErrorResponse("Error creating product", err.Error())
 		return c.Status(fiber.StatusInternalServerError).JSON(response)
 	}
@@ -148,7 +151,7 @@ func AddProduct(c *fiber.Ctx) error {
 
 // UpdateProduct godoc
 // @Summary Update a product
-// @Description Update a product's details. Only accessible by users with the 'admin' role.
+// @Description Update a product's details. Requires the product:update permission.
 // @Tags admin
 // @Accept json
 // @Produce json
@@ -160,9 +163,9 @@ func AddProduct(c *fiber.Ctx) error {
 // @Failure 403 {object} dto.GeneralResponse "Forbidden. Only admin can access this endpoint."
 // @Router /admin/product/{id} [patch]
 // @Security BearerAuth
-func UpdateProduct(c *fiber.Ctx) error {
+// @Security ApiKeyAuth
+func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 	productID := c.Params("id")
-	db := database.Database.Db
 	productUUID, err := utils.CheckUUID(productID)
 	if err != nil {
 		response := dto.NewErrorResponse("Invalid Product ID", err.Error())
@@ -181,29 +184,29 @@ func UpdateProduct(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response)
 	}
 
-	var product models.Product
-	if err := service.GetProductByID(&product, *productUUID, db); err != nil {
+	product, err := h.catalog.GetProductByID(c.UserContext(), *productUUID)
+	if err != nil {
 		response := dto.NewErrorResponse("Product not found", err.Error())
 		return c.Status(fiber.StatusNotFound).JSON(response)
 	}
 
-	if err := copier.CopyWithOption(&product, &updateProduct, copier.Option{IgnoreEmpty: true}); err != nil {
+	if err := copier.CopyWithOption(product, &updateProduct, copier.Option{IgnoreEmpty: true}); err != nil {
 		response := dto.NewErrorResponse("Error updating product", err.Error())
 		return c.Status(fiber.StatusInternalServerError).JSON(response)
 	}
 
-	if err := service.UpdateProduct(&product, db); err != nil {
+	if err := h.catalog.UpdateProduct(c.UserContext(), product); err != nil {
 		response := dto.NewErrorResponse("Error updating product", err.Error())
 		return c.Status(fiber.StatusInternalServerError).JSON(response)
 	}
 
-	response := dto.NewSuccessResponse(dto.NewResponseProduct(&product), "Product updated successfully")
+	response := dto.NewSuccessResponse(dto.NewResponseProduct(product), "Product updated successfully")
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
 // DeleteProduct godoc
 // @Summary Delete a product
-// @Description Delete a product by its ID. Only accessible by users with the 'admin' role.
+// @Description Delete a product by its ID. Requires the product:delete permission.
 // @Tags admin
 // @Produce json
 // @Param id path string true "Product ID"
@@ -211,10 +214,10 @@ func UpdateProduct(c *fiber.Ctx) error {
 // @Failure 404 {object} dto.GeneralResponse "Product not found"
 // @Router /admin/product/{id} [delete]
 // @Security BearerAuth
-func DeleteProduct(c *fiber.Ctx) error {
+// @Security ApiKeyAuth
+func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 	// Get the product ID from the URL path
 	productID := c.Params("id")
-	db := database.Database.Db
 	productUUID, err := utils.CheckUUID(productID)
 	if err != nil {
 		response := dto.NewErrorResponse("Invalid Product ID", err.Error())
@@ -222,15 +225,15 @@ func DeleteProduct(c *fiber.Ctx) error {
 	}
 
 	// Fetch the product from the database
-	var product models.Product
-	if err := service.GetProductByID(&product, *productUUID, db); err != nil {
+	product, err := h.catalog.GetProductByID(c.UserContext(), *productUUID)
+	if err != nil {
 		// If the product is not found, return a 404 response
 		response := dto.NewErrorResponse("Product not found", err.Error())
 		return c.Status(fiber.StatusNotFound).JSON(response)
 	}
 
 	// Delete the product
-	if err := service.DeleteProduct(&product, db); err != nil {
+	if err := h.catalog.DeleteProduct(c.UserContext(), product); err != nil {
 		response := dto.NewErrorResponse("Error deleting product", err.Error())
 		return c.Status(fiber.StatusInternalServerError).JSON(response)
 	}
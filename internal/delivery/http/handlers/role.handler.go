@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetRoles godoc
+// @Summary List roles
+// @Description List the roles that can be assigned to users and the permissions each one grants
+// @Tags admin
+// @Produce json
+// @Success 200 {array} dto.ResponseRole "Roles and their permissions"
+// @Router /admin/roles [get]
+// @Security BearerAuth
+func GetRoles(c *fiber.Ctx) error {
+	roles := make([]dto.ResponseRole, 0, len(models.Roles))
+	for _, role := range models.Roles {
+		roles = append(roles, dto.NewResponseRole(role, models.RolePermissions[role]))
+	}
+	return c.JSON(dto.NewSuccessResponse(roles, "Roles retrieved successfully"))
+}
+
+// AssignRole godoc
+// @Summary Assign a role to a user
+// @Description Change the role of a user. The user is signed out so the new permissions apply immediately.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param roleDTO body dto.RequestAssignRole true "New role"
+// @Success 200 {object} dto.ResponseUser "Updated user"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 404 {object} dto.GeneralResponse "Error Message"
+// @Failure 409 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/users/{id}/role [put]
+// @Security BearerAuth
+func AssignRole(c *fiber.Ctx) error {
+	actorID := c.Locals("userID").(uuid.UUID)
+
+	userID, err := utils.CheckUUID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid user ID", err.Error()))
+	}
+
+	var roleDTO dto.RequestAssignRole
+	if err := c.BodyParser(&roleDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(roleDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+	}
+
+	user, err := service.AssignRole(*userID, roleDTO.Role, actorID)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidRole, service.ErrCannotChangeOwnRole:
+			return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Could not assign role", err.Error()))
+		case service.ErrLastAdmin:
+			return c.Status(fiber.StatusConflict).JSON(dto.NewErrorResponse("Could not assign role", err.Error()))
+		case service.ErrUserNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("User not found", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not assign role", err.Error()))
+	}
+
+	return c.JSON(dto.NewSuccessResponse(dto.NewResponseUser(user), "Role assigned successfully"))
+}
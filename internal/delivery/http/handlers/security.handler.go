@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// UnlockLogin godoc
+// @Summary Lift a login lockout
+// @Description Clear the failed login counters and lockout of an account, an IP address or both
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param unlockDTO body dto.RequestUnlockLogin true "Email and/or IP address to unlock"
+// @Success 200 {object} dto.GeneralResponse "Success Message"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 500 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/lockouts/unlock [post]
+// @Security BearerAuth
+func UnlockLogin(c *fiber.Ctx) error {
+	adminID := c.Locals("userID").(uuid.UUID)
+
+	var unlockDTO dto.RequestUnlockLogin
+	if err := c.BodyParser(&unlockDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(unlockDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+	}
+
+	if err := service.UnlockLogin(unlockDTO.Email, unlockDTO.IP, adminID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not unlock login", err.Error()))
+	}
+
+	return c.JSON(dto.NewSuccessResponse(nil, "Login unlocked successfully"))
+}
+
+// GetSecurityEvents godoc
+// @Summary List security events
+// @Description List lockout and unlock events, newest first
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(20)
+// @Success 200 {array} dto.ResponseSecurityEvent "Security events"
+// @Failure 500 {object} dto.GeneralResponse "Error Message"
+// @Router /admin/lockouts/events [get]
+// @Security BearerAuth
+func GetSecurityEvents(c *fiber.Ctx) error {
+	query, page, limit := utils.GetPaginatedQuery(&models.SecurityEvent{}, c.Query("page", "1"), c.Query("limit", "20"))
+
+	var totalData int64
+	query.Count(&totalData)
+
+	var events []models.SecurityEvent
+	if err := service.GetSecurityEvents(&events, query); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Error getting security events", err.Error()))
+	}
+
+	eventDTOs := make([]dto.ResponseSecurityEvent, 0, len(events))
+	for _, event := range events {
+		eventDTOs = append(eventDTOs, dto.NewResponseSecurityEvent(&event))
+	}
+
+	paginatedResponse := dto.ResponsePaginated[dto.ResponseSecurityEvent]{
+		Meta: dto.PaginatedMeta{
+			Limit: limit,
+			Total: int(totalData),
+			Page:  page,
+		},
+		List: eventDTOs,
+	}
+	return c.JSON(dto.NewSuccessResponse(paginatedResponse, "Security events retrieved successfully"))
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// SessionHandler serves the endpoints listing and signing out the sessions of
+// the signed in user
+type SessionHandler struct {
+	sessions *service.SessionService
+}
+
+func NewSessionHandler(sessions *service.SessionService) *SessionHandler {
+	return &SessionHandler{sessions: sessions}
+}
+
+// GetSessions godoc
+// @Summary List active sessions
+// @Description List the devices the user is logged in on, most recently used first
+// @Tags user
+// @Produce json
+// @Success 200 {array} dto.ResponseSession "Active sessions"
+// @Failure 401 {object} dto.GeneralResponse "Unauthorized"
+// @Router /user/sessions [get]
+// @Security BearerAuth
+func (h *SessionHandler) GetSessions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+	claims := c.Locals("claims").(*utils.Claims)
+
+	sessions, err := h.sessions.GetSessions(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Error retrieving sessions", err.Error()))
+	}
+
+	sessionDTOs := make([]dto.ResponseSession, 0, len(sessions))
+	for _, session := range sessions {
+		sessionDTOs = append(sessionDTOs, dto.NewResponseSession(&session, session.ID.String() == claims.SessionID))
+	}
+	return c.JSON(dto.NewSuccessResponse(sessionDTOs, "Sessions retrieved successfully"))
+}
+
+// RevokeSession godoc
+// @Summary Sign out a session
+// @Description Sign out one of the user's sessions, e.g. a lost device
+// @Tags user
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} dto.GeneralResponse "Success Message"
+// @Failure 400 {object} dto.GeneralResponse "Error Message"
+// @Failure 404 {object} dto.GeneralResponse "Error Message"
+// @Router /user/sessions/{id} [delete]
+// @Security BearerAuth
+func (h *SessionHandler) RevokeSession(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	sessionID, err := utils.CheckUUID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid session ID", err.Error()))
+	}
+
+	if err := h.sessions.RevokeSession(c.UserContext(), userID, *sessionID); err != nil {
+		if err == service.ErrSessionNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("Session not found", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not sign out session", err.Error()))
+	}
+
+	return c.JSON(dto.NewSuccessResponse(nil, "Session signed out successfully"))
+}
+
+// RevokeAllSessions godoc
+// @Summary Sign out everywhere
+// @Description Sign out every session of the user, including the current one
+// @Tags user
+// @Produce json
+// @Success 200 {object} dto.GeneralResponse "Success Message"
+// @Failure 500 {object} dto.GeneralResponse "Error Message"
+// @Router /user/sessions [delete]
+// @Security BearerAuth
+func (h *SessionHandler) RevokeAllSessions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	if err := h.sessions.SignOutEverywhere(c.UserContext(), userID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not sign out", err.Error()))
+	}
+
+	return c.JSON(dto.NewSuccessResponse(nil, "Signed out of every session"))
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// SetupTwoFactor godoc
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and its otpauth URI to scan with an authenticator app. Enrollment is completed with /user/2fa/confirm.
+// @Tags user
+// @Produce  json
+// @Success 200 {object} dto.GeneralResponse "Secret and otpauth URI"
+// @Failure 401 {object} dto.GeneralResponse "Unauthorized"
+// @Failure 409 {object} dto.GeneralResponse "Already enabled"
+// @Router /user/2fa/setup [post]
+// @Security BearerAuth
+func SetupTwoFactor(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	setup, err := service.SetupTwoFactor(userID)
+	if err != nil {
+		if err == service.ErrTwoFactorAlreadyEnabled {
+			return c.Status(fiber.StatusConflict).JSON(dto.NewErrorResponse("Two-factor authentication already enabled", err.Error()))
+		}
+		if err == service.ErrUserNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("User not found", err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Could not start two-factor setup", err.Error()))
+	}
+
+	response := dto.NewSuccessResponse(dto.ResponseTwoFactorSetup{Secret: setup.Secret, OtpauthURI: setup.URI}, "Scan the secret with your authenticator app and confirm with a code")
+	return c.JSON(response)
+}
+
+// ConfirmTwoFactor godoc
+// @Summary Confirm two-factor enrollment
+// @Description Enable two-factor authentication with a code from the authenticator app. Returns recovery codes that are only shown once.
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param codeDTO body dto.RequestTwoFactorCode true "Authenticator code"
+// @Success 200 {object} dto.GeneralResponse "Recovery codes"
+// @Failure 400 {object} dto.GeneralResponse "Invalid code"
+// @Failure 401 {object} dto.GeneralResponse "Unauthorized"
+// @Router /user/2fa/confirm [post]
+// @Security BearerAuth
+func ConfirmTwoFactor(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	var codeDTO dto.RequestTwoFactorCode
+	if err := c.BodyParser(&codeDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(codeDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+	}
+
+	codes, err := service.ConfirmTwoFactor(userID, codeDTO.Code)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	response := dto.NewSuccessResponse(dto.ResponseRecoveryCodes{RecoveryCodes: codes}, "Two-factor authentication enabled, log in again to use it")
+	return c.JSON(response)
+}
+
+// DisableTwoFactor godoc
+// @Summary Disable two-factor authentication
+// @Description Disable two-factor authentication with a code from the authenticator app or a recovery code
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param codeDTO body dto.RequestTwoFactorCode true "Authenticator or recovery code"
+// @Success 200 {object} dto.GeneralResponse "Success Message"
+// @Failure 400 {object} dto.GeneralResponse "Invalid code"
+// @Failure 403 {object} dto.GeneralResponse "Two-factor authentication is mandatory"
+// @Router /user/2fa/disable [post]
+// @Security BearerAuth
+func DisableTwoFactor(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	var codeDTO dto.RequestTwoFactorCode
+	if err := c.BodyParser(&codeDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(codeDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+	}
+
+	if err := service.DisableTwoFactor(userID, codeDTO.Code); err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(dto.NewSuccessResponse(nil, "Two-factor authentication disabled"))
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace every recovery code with a new set, confirmed with a code from the authenticator app
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param codeDTO body dto.RequestTwoFactorCode true "Authenticator code"
+// @Success 200 {object} dto.GeneralResponse "Recovery codes"
+// @Failure 400 {object} dto.GeneralResponse "Invalid code"
+// @Router /user/2fa/recovery-codes [post]
+// @Security BearerAuth
+func RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	var codeDTO dto.RequestTwoFactorCode
+	if err := c.BodyParser(&codeDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(codeDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Validation error", err.Error()))
+	}
+
+	codes, err := service.RegenerateRecoveryCodes(userID, codeDTO.Code)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(dto.NewSuccessResponse(dto.ResponseRecoveryCodes{RecoveryCodes: codes}, "Recovery codes regenerated"))
+}
+
+func twoFactorError(c *fiber.Ctx, err error) error {
+	switch err {
+	case service.ErrInvalidTwoFactorCode:
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse("Invalid authentication code", err.Error()))
+	case service.ErrTwoFactorNotInitiated, service.ErrTwoFactorNotEnabled, service.ErrTwoFactorAlreadyEnabled:
+		return c.Status(fiber.StatusConflict).JSON(dto.NewErrorResponse("Invalid two-factor state", err.Error()))
+	case service.ErrTwoFactorMandatory:
+		return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse("Two-factor authentication is mandatory", err.Error()))
+	case service.ErrUserNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("User not found", err.Error()))
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse("Two-factor operation failed", err.Error()))
+}
@@ -8,6 +8,15 @@ import (
 	"github.com/google/uuid"
 )
 
+// UserHandler serves the profile endpoints of the signed in user
+type UserHandler struct {
+	users *service.UserService
+}
+
+func NewUserHandler(users *service.UserService) *UserHandler {
+	return &UserHandler{users: users}
+}
+
 // GetMe godoc
 // @Summary Get current authenticated user
 // @Description Get the details of the currently authenticated user
@@ -18,11 +27,11 @@ import (
 // @Failure 401 {object} dto.GeneralResponse "Unauthorized"
 // @Router /user/me [get]
 // @Security BearerAuth
-func GetMe(c *fiber.Ctx) error {
+func (h *UserHandler) GetMe(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uuid.UUID)
 
 	// Fetch the user using the service
-	user, err := service.GetUserByID(userID)
+	user, err := h.users.GetUser(c.UserContext(), userID)
 	if err != nil {
 		if err == service.ErrUserNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("User not found", err.Error()))
@@ -47,7 +56,7 @@ func GetMe(c *fiber.Ctx) error {
 // @Failure 401 {object} dto.GeneralResponse "Error Message"
 // @Router /user/update [patch]
 // @Security BearerAuth
-func UpdateUser(c *fiber.Ctx) error {
+func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uuid.UUID)
 
 	var updateData dto.RequestUpdateUser
@@ -56,7 +65,7 @@ func UpdateUser(c *fiber.Ctx) error {
 	}
 
 	// Use the service layer to update the user
-	user, err := service.UpdateUserDetails(userID, updateData.Name)
+	user, err := h.users.UpdateUserDetails(c.UserContext(), userID, updateData.Name)
 	if err != nil {
 		if err == service.ErrUserNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse("User not found", err.Error()))
@@ -80,7 +89,7 @@ func UpdateUser(c *fiber.Ctx) error {
 // @Failure 401 {object} dto.GeneralResponse "Error Message"
 // @Router /user/change-password [patch]
 // @Security BearerAuth
-func UpdatePassword(c *fiber.Ctx) error {
+func (h *UserHandler) UpdatePassword(c *fiber.Ctx) error {
 	var updatePasswordDTO dto.RequestUpdatePassword
 	if err := c.BodyParser(&updatePasswordDTO); err != nil {
 		return c.Status(400).JSON(dto.NewErrorResponse("Cannot parse JSON", err.Error()))
@@ -95,7 +104,7 @@ func UpdatePassword(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uuid.UUID)
 
 	// Use the service layer to update the password
-	err := service.UpdateUserPassword(userID, updatePasswordDTO.CurrentPassword, updatePasswordDTO.NewPassword)
+	err := h.users.UpdateUserPassword(c.UserContext(), userID, updatePasswordDTO.CurrentPassword, updatePasswordDTO.NewPassword)
 	if err != nil {
 		if err == service.ErrUserNotFound {
 			return c.Status(404).JSON(dto.NewErrorResponse("User not found", err.Error()))
@@ -2,18 +2,42 @@ package router
 
 import (
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/handlers"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
 	"github.com/arsyaputraa/go-synapsis-challenge/pkg/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
-func adminRoutes(app *fiber.App) {
+func adminRoutes(app *fiber.App, h Handlers) {
 	// grouping
 	api := app.Group("/api")
-	admin := api.Group("/admin", middleware.JWTMiddleware, middleware.RoleMiddleware("admin"))
-	admin.Post("/product", handlers.AddProduct)
-	admin.Patch("/product/:id", handlers.UpdateProduct)
-	admin.Delete("/product/:id", handlers.DeleteProduct)
-	admin.Post("/category", handlers.AddCategory)
-	admin.Patch("/category/:id", handlers.UpdateCategory)
-	admin.Delete("/category/:id", handlers.DeleteCategory)
+	admin := api.Group("/admin")
+	admin.Post("/product", withPermission(models.PermProductCreate, h.Products.AddProduct)...)
+	admin.Patch("/product/:id", withPermission(models.PermProductUpdate, h.Products.UpdateProduct)...)
+	admin.Delete("/product/:id", withPermission(models.PermProductDelete, h.Products.DeleteProduct)...)
+	admin.Post("/category", withPermission(models.PermCategoryCreate, h.Categories.AddCategory)...)
+	admin.Patch("/category/:id", withPermission(models.PermCategoryUpdate, h.Categories.UpdateCategory)...)
+	admin.Delete("/category/:id", withPermission(models.PermCategoryDelete, h.Categories.DeleteCategory)...)
+	admin.Get("/orders", withPermission(models.PermOrderRead, h.Orders.GetAllOrders)...)
+	admin.Post("/lockouts/unlock", withPermission(models.PermLockoutManage, handlers.UnlockLogin)...)
+	admin.Get("/lockouts/events", withPermission(models.PermLockoutManage, handlers.GetSecurityEvents)...)
+	admin.Post("/api-keys", withPermission(models.PermAPIKeyManage, handlers.CreateAPIKey)...)
+	admin.Get("/api-keys", withPermission(models.PermAPIKeyManage, handlers.GetAPIKeys)...)
+	admin.Post("/api-keys/:id/rotate", withPermission(models.PermAPIKeyManage, handlers.RotateAPIKey)...)
+	admin.Delete("/api-keys/:id", withPermission(models.PermAPIKeyManage, handlers.RevokeAPIKey)...)
+	admin.Get("/impersonation-logs", withPermission(models.PermAuditRead, handlers.GetImpersonationLogs)...)
+	admin.Get("/roles", withPermission(models.PermRoleAssign, handlers.GetRoles)...)
+	admin.Get("/users", withPermission(models.PermUserRead, handlers.GetUsers)...)
+	admin.Get("/users/:id", withPermission(models.PermUserRead, h.Users.GetUser)...)
+	admin.Delete("/users/:id", withPermission(models.PermUserManage, handlers.DeleteUser)...)
+	admin.Put("/users/:id/role", withPermission(models.PermRoleAssign, handlers.AssignRole)...)
+	admin.Post("/users/:id/impersonate", withPermission(models.PermUserImpersonate, handlers.ImpersonateUser)...)
+	admin.Post("/users/:id/suspend", withPermission(models.PermUserManage, handlers.SuspendUser)...)
+	admin.Post("/users/:id/reactivate", withPermission(models.PermUserManage, handlers.ReactivateUser)...)
+	admin.Post("/users/:id/force-password-reset", withPermission(models.PermUserManage, handlers.ForcePasswordReset)...)
+}
+
+// withPermission lets users whose role grants permission, or API keys whose
+// scopes grant it, call handler
+func withPermission(permission string, handler fiber.Handler) []fiber.Handler {
+	return []fiber.Handler{middleware.APIKeyMiddleware, middleware.JWTMiddleware, middleware.TwoFactorMiddleware, middleware.RequirePermission(permission), handler}
 }
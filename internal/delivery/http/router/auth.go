@@ -2,14 +2,24 @@ package router
 
 import (
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/handlers"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
 // SetupRoutes func
-func authRoutes(app *fiber.App) {
+func authRoutes(app *fiber.App, h Handlers) {
 	// grouping
 	api := app.Group("/api")
 	auth := api.Group("/auth")
-	auth.Post("/register", handlers.Register)
-	auth.Post("/login", handlers.Login)
+	auth.Post("/register", h.Auth.Register)
+	auth.Post("/login", h.Auth.Login)
+	auth.Post("/login/2fa", h.Auth.LoginTwoFactor)
+	auth.Post("/refresh", handlers.RefreshToken)
+	auth.Post("/logout", middleware.AllowWhileImpersonating, middleware.JWTMiddleware, h.Auth.Logout)
+	auth.Get("/verify", handlers.VerifyEmail)
+	auth.Post("/verify/resend", handlers.ResendVerification)
+	auth.Post("/forgot-password", handlers.ForgotPassword)
+	auth.Post("/reset-password", handlers.ResetPassword)
+	auth.Get("/oidc/:provider/login", handlers.OIDCLogin)
+	auth.Get("/oidc/:provider/callback", h.Auth.OIDCCallback)
 }
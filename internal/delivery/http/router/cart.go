@@ -1,19 +1,18 @@
 package router
 
 import (
-	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/handlers"
 	"github.com/arsyaputraa/go-synapsis-challenge/pkg/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
 // SetupRoutes func
-func cartRoutes(app *fiber.App) {
+func cartRoutes(app *fiber.App, h Handlers) {
 	// grouping
 	api := app.Group("/api")
 	cart := api.Group("/cart", middleware.JWTMiddleware)
-	cart.Post("/", handlers.AddToCart)
-	cart.Get("/", handlers.GetCartItems)
-	cart.Get("/:id", handlers.GetCartItemById)
+	cart.Post("/", h.Carts.AddToCart)
+	cart.Get("/", h.Carts.GetCartItems)
+	cart.Get("/:id", h.Carts.GetCartItemById)
 
-	cart.Delete("/:id", handlers.RemoveCartItems)
+	cart.Delete("/:id", h.Carts.RemoveCartItems)
 }
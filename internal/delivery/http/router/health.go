@@ -0,0 +1,11 @@
+package router
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/handlers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func healthRoutes(app *fiber.App) {
+	app.Get("/healthz", handlers.GetHealth)
+	app.Get("/readyz", handlers.GetReadiness)
+}
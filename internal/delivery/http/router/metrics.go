@@ -0,0 +1,10 @@
+package router
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/handlers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func metricsRoutes(app *fiber.App) {
+	app.Get("/metrics", handlers.GetMetrics)
+}
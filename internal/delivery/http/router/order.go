@@ -1,17 +1,16 @@
 package router
 
 import (
-	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/handlers"
 	"github.com/arsyaputraa/go-synapsis-challenge/pkg/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
 // SetupRoutes func
-func orderRoutes(app *fiber.App) {
+func orderRoutes(app *fiber.App, h Handlers) {
 	// grouping
 	api := app.Group("/api")
 	order := api.Group("/order", middleware.JWTMiddleware)
 
-	order.Post("/checkout", handlers.CheckoutOrder)
-	order.Get("/", handlers.GetUserOrders)
+	order.Post("/checkout", h.Orders.CheckoutOrder)
+	order.Get("/", h.Orders.GetUserOrders)
 }
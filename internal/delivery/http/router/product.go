@@ -1,15 +1,14 @@
 package router
 
 import (
-	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/handlers"
 	"github.com/gofiber/fiber/v2"
 )
 
 // SetupRoutes func
-func productRoutes(app *fiber.App) {
+func productRoutes(app *fiber.App, h Handlers) {
 	// grouping
 	api := app.Group("/api")
 	product := api.Group("/product")
-	product.Get("/", handlers.GetProductList)
-	product.Get("/:id", handlers.GetProduct)
+	product.Get("/", h.Products.GetProductList)
+	product.Get("/:id", h.Products.GetProduct)
 }
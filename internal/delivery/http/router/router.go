@@ -1,25 +1,63 @@
 package router
 
 import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/handlers"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
 	"github.com/gofiber/fiber/v2"
 )
 
+// Handlers are the handlers that depend on services, built by NewHandlers
+type Handlers struct {
+	Auth       *handlers.AuthHandler
+	Sessions   *handlers.SessionHandler
+	Users      *handlers.UserHandler
+	Products   *handlers.ProductHandler
+	Categories *handlers.CategoryHandler
+	Carts      *handlers.CartHandler
+	Orders     *handlers.OrderHandler
+	Payments   *handlers.PaymentHandler
+}
+
+// NewHandlers builds the services on top of store and the handlers using them
+func NewHandlers(store repository.Store, auth config.AuthSettings) Handlers {
+	catalog := service.NewCatalogService(store)
+	payments := service.NewPaymentService(store)
+	return Handlers{
+		Auth:       handlers.NewAuthHandler(service.NewAuthService(store)),
+		Sessions:   handlers.NewSessionHandler(service.NewSessionService(store)),
+		Users:      handlers.NewUserHandler(service.NewUserService(store)),
+		Products:   handlers.NewProductHandler(catalog),
+		Categories: handlers.NewCategoryHandler(catalog),
+		Carts:      handlers.NewCartHandler(service.NewCartService(store)),
+		Orders:     handlers.NewOrderHandler(service.NewOrderService(store, payments, auth.RequireVerifiedEmailForCheckout)),
+		Payments:   handlers.NewPaymentHandler(payments),
+	}
+}
+
 // SetupRoutes func
-func SetupRoutes(app *fiber.App) {
+func SetupRoutes(app *fiber.App, h Handlers) {
 	// user
-	userRoutes(app)
+	userRoutes(app, h)
 	// auth
-	authRoutes(app)
+	authRoutes(app, h)
 	// product
-	productRoutes(app)
+	productRoutes(app, h)
 	// admin
-	adminRoutes(app)
+	adminRoutes(app, h)
 	// cart
-	cartRoutes(app)
+	cartRoutes(app, h)
 	// order
-	orderRoutes(app)
+	orderRoutes(app, h)
 	// category
-	categoryRoutes(app)
+	categoryRoutes(app, h)
 	// webhook
-	webhookRoutes(app)
+	webhookRoutes(app, h)
+	// well-known
+	wellKnownRoutes(app)
+	// metrics
+	metricsRoutes(app)
+	// health
+	healthRoutes(app)
 }
@@ -7,11 +7,20 @@ import (
 )
 
 // SetupRoutes func
-func userRoutes(app *fiber.App) {
+func userRoutes(app *fiber.App, h Handlers) {
 	// grouping
 	api := app.Group("/api")
 	user := api.Group("/user", middleware.JWTMiddleware)
-	user.Get("/me", handlers.GetMe)
-	user.Patch("/update", handlers.UpdateUser)
-	user.Patch("change-password", handlers.UpdatePassword)
+	user.Get("/me", h.Users.GetMe)
+	user.Delete("/me", handlers.DeleteMe)
+	user.Get("/export", handlers.ExportUserData)
+	user.Patch("/update", h.Users.UpdateUser)
+	user.Patch("change-password", h.Users.UpdatePassword)
+	user.Post("/2fa/setup", handlers.SetupTwoFactor)
+	user.Post("/2fa/confirm", handlers.ConfirmTwoFactor)
+	user.Post("/2fa/disable", handlers.DisableTwoFactor)
+	user.Post("/2fa/recovery-codes", handlers.RegenerateRecoveryCodes)
+	user.Get("/sessions", h.Sessions.GetSessions)
+	user.Delete("/sessions", h.Sessions.RevokeAllSessions)
+	user.Delete("/sessions/:id", h.Sessions.RevokeSession)
 }
@@ -1,14 +1,13 @@
 package router
 
 import (
-	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/handlers"
 	"github.com/gofiber/fiber/v2"
 )
 
-func webhookRoutes(app *fiber.App) {
+func webhookRoutes(app *fiber.App, h Handlers) {
 	// grouping
 	api := app.Group("/api")
 	webhook := api.Group("/webhook")
-	webhook.Get("/payment", handlers.PaymentWebhook)
+	webhook.Get("/payment", h.Payments.PaymentWebhook)
 
 }
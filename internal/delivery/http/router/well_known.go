@@ -0,0 +1,12 @@
+package router
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/handlers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func wellKnownRoutes(app *fiber.App) {
+	// grouping
+	wellKnown := app.Group("/.well-known")
+	wellKnown.Get("/jwks.json", handlers.GetJWKS)
+}
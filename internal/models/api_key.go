@@ -0,0 +1,55 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ScopeCatalogWrite string = "catalog:write"
+	ScopeOrdersRead   string = "orders:read"
+)
+
+// APIKeyScopes lists every scope that can be granted to an API key
+var APIKeyScopes = []string{ScopeCatalogWrite, ScopeOrdersRead}
+
+// APIKey authenticates machine-to-machine integrations. Only a hash of the key
+// is stored; the key itself is shown once when created or rotated.
+type APIKey struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"` // Use UUID as the primary key
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	Name           string     `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix         string     `gorm:"type:varchar(16);not null" json:"prefix"`
+	KeyHash        string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	Scopes         string     `gorm:"type:text;not null" json:"scopes"` // Space separated
+	CreatedByRefer uuid.UUID  `gorm:"type:uuid;index" json:"created_by"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	LastUsedIP     string     `gorm:"type:varchar(64)" json:"last_used_ip"`
+}
+
+func (apiKey *APIKey) BeforeCreate(tx *gorm.DB) (err error) {
+	// Generate a new UUID and assign it to the ID field
+	apiKey.ID = uuid.New()
+	return
+}
+
+// ScopeList returns the scopes granted to the key
+func (apiKey *APIKey) ScopeList() []string {
+	return strings.Fields(apiKey.Scopes)
+}
+
+// HasPermission reports whether one of the key's scopes grants permission
+func (apiKey *APIKey) HasPermission(permission string) bool {
+	for _, scope := range apiKey.ScopeList() {
+		if containsPermission(ScopePermissions[scope], permission) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ImpersonationLog records a request made by staff while impersonating a user
+type ImpersonationLog struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"` // Use UUID as the primary key
+	CreatedAt    time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+	ActorRefer   uuid.UUID `gorm:"type:uuid;index;not null" json:"actor_id"`
+	SubjectRefer uuid.UUID `gorm:"type:uuid;index;not null" json:"user_id"`
+	// TokenID is the jti of the impersonation token used
+	TokenID string `gorm:"type:varchar(64);index" json:"token_id"`
+	Method  string `gorm:"type:varchar(10)" json:"method"`
+	Path    string `gorm:"type:varchar(255)" json:"path"`
+	Status  int    `json:"status"`
+	IP      string `gorm:"type:varchar(64)" json:"ip"`
+	// Blocked is set for write requests refused because of the impersonation
+	Blocked bool `gorm:"not null;default:false" json:"blocked"`
+}
+
+func (impersonationLog *ImpersonationLog) BeforeCreate(tx *gorm.DB) (err error) {
+	// Generate a new UUID and assign it to the ID field
+	impersonationLog.ID = uuid.New()
+	return
+}
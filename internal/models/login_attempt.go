@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// LoginAttempt tracks failed logins for a key such as "email:<address>" or "ip:<address>"
+type LoginAttempt struct {
+	Key           string     `gorm:"column:attempt_key;type:varchar(255);primaryKey" json:"key"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time  `gorm:"not null" json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PasswordResetToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"` // Use UUID as the primary key
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+	UserRefer uuid.UUID  `json:"user_id" gorm:"type:uuid;index;not null"`
+	User      User       `gorm:"foreignKey:UserRefer"`
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+func (passwordResetToken *PasswordResetToken) BeforeCreate(tx *gorm.DB) (err error) {
+	// Generate a new UUID and assign it to the ID field
+	passwordResetToken.ID = uuid.New()
+	return
+}
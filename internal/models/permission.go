@@ -0,0 +1,72 @@
+package models
+
+const (
+	PermProductCreate   string = "product:create"
+	PermProductUpdate   string = "product:update"
+	PermProductDelete   string = "product:delete"
+	PermCategoryCreate  string = "category:create"
+	PermCategoryUpdate  string = "category:update"
+	PermCategoryDelete  string = "category:delete"
+	PermOrderRead       string = "order:read"
+	PermLockoutManage   string = "lockout:manage"
+	PermAPIKeyManage    string = "api_key:manage"
+	PermRoleAssign      string = "role:assign"
+	PermUserRead        string = "user:read"
+	PermUserManage      string = "user:manage"
+	PermUserImpersonate string = "user:impersonate"
+	PermAuditRead       string = "audit:read"
+)
+
+// Permissions lists every permission known to the API
+var Permissions = []string{
+	PermProductCreate, PermProductUpdate, PermProductDelete,
+	PermCategoryCreate, PermCategoryUpdate, PermCategoryDelete,
+	PermOrderRead, PermLockoutManage, PermAPIKeyManage, PermRoleAssign,
+	PermUserRead, PermUserManage, PermUserImpersonate, PermAuditRead,
+}
+
+// Roles lists every role that can be assigned to a user
+var Roles = []string{Admin, CatalogManager, OrderOperator, SupportAgent, WarehouseStaff, Customer}
+
+// RolePermissions maps each role to the permissions it grants. Customers only
+// use their own account and have none.
+var RolePermissions = map[string][]string{
+	Admin: Permissions,
+	CatalogManager: {
+		PermProductCreate, PermProductUpdate, PermProductDelete,
+		PermCategoryCreate, PermCategoryUpdate, PermCategoryDelete,
+	},
+	OrderOperator:  {PermOrderRead},
+	SupportAgent:   {PermOrderRead, PermLockoutManage, PermUserRead, PermUserManage},
+	WarehouseStaff: {PermOrderRead, PermProductUpdate},
+	Customer:       {},
+}
+
+// ScopePermissions maps each API key scope to the permissions it grants
+var ScopePermissions = map[string][]string{
+	ScopeCatalogWrite: {
+		PermProductCreate, PermProductUpdate, PermProductDelete,
+		PermCategoryCreate, PermCategoryUpdate, PermCategoryDelete,
+	},
+	ScopeOrdersRead: {PermOrderRead},
+}
+
+// IsValidRole reports whether role is one of Roles
+func IsValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+// RoleHasPermission reports whether role grants permission
+func RoleHasPermission(role, permission string) bool {
+	return containsPermission(RolePermissions[role], permission)
+}
+
+func containsPermission(permissions []string, permission string) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RecoveryCode is a single-use code that replaces a TOTP code when the authenticator is lost
+type RecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"` // Use UUID as the primary key
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+	UserRefer uuid.UUID  `json:"user_id" gorm:"type:uuid;index;not null"`
+	User      User       `gorm:"foreignKey:UserRefer"`
+	CodeHash  string     `gorm:"type:varchar(64);not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+func (recoveryCode *RecoveryCode) BeforeCreate(tx *gorm.DB) (err error) {
+	// Generate a new UUID and assign it to the ID field
+	recoveryCode.ID = uuid.New()
+	return
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RefreshToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"` // Use UUID as the primary key
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UserRefer uuid.UUID `json:"user_id" gorm:"type:uuid;index;not null"`
+	User      User      `gorm:"foreignKey:UserRefer"`
+	// SessionRefer is shared by every token of a rotation chain
+	SessionRefer *uuid.UUID `gorm:"type:uuid;index" json:"session_id"`
+	TokenHash    string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedBy   *uuid.UUID `gorm:"type:uuid" json:"replaced_by"`
+	// MFA is carried over to the access tokens minted from this refresh token
+	MFA bool `gorm:"not null;default:false" json:"mfa"`
+}
+
+func (refreshToken *RefreshToken) BeforeCreate(tx *gorm.DB) (err error) {
+	// Generate a new UUID and assign it to the ID field
+	refreshToken.ID = uuid.New()
+	return
+}
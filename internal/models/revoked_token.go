@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevokedToken holds the IDs (jti) of access tokens revoked before their expiry
+// and of login challenges already completed
+type RevokedToken struct {
+	TokenID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"token_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UserRefer uuid.UUID `json:"user_id" gorm:"type:uuid;index"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	EventAccountLocked  string = "account_locked"
+	EventIPLocked       string = "ip_locked"
+	EventLockoutCleared string = "lockout_cleared"
+	// EventImpersonationStarted is recorded when staff obtain a token to act as a user
+	EventImpersonationStarted string = "impersonation_started"
+)
+
+// SecurityEvent records authentication events worth reviewing, such as lockouts
+type SecurityEvent struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"` // Use UUID as the primary key
+	CreatedAt time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
+	Type      string     `gorm:"type:varchar(50);index;not null" json:"type"`
+	Email     string     `gorm:"type:varchar(100);index" json:"email"`
+	IP        string     `gorm:"type:varchar(64)" json:"ip"`
+	UserRefer *uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	Detail    string     `gorm:"type:text" json:"detail"`
+}
+
+func (securityEvent *SecurityEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	// Generate a new UUID and assign it to the ID field
+	securityEvent.ID = uuid.New()
+	return
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Session is one login of a user on a device. It lives as long as its chain of
+// refresh tokens and every access token carries its ID.
+type Session struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"` // Use UUID as the primary key
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UserRefer    uuid.UUID  `json:"user_id" gorm:"type:uuid;index;not null"`
+	User         User       `gorm:"foreignKey:UserRefer" json:"-"`
+	UserAgent    string     `gorm:"type:varchar(255)" json:"user_agent"`
+	IP           string     `gorm:"type:varchar(64)" json:"ip"`
+	LastActiveAt time.Time  `gorm:"not null" json:"last_active_at"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+}
+
+func (session *Session) BeforeCreate(tx *gorm.DB) (err error) {
+	// Generate a new UUID and assign it to the ID field
+	session.ID = uuid.New()
+	return
+}
@@ -9,18 +9,36 @@ import (
 )
 
 const (
-	Admin    string = "admin"
-	Customer string = "customer"
+	Admin          string = "admin"
+	Customer       string = "customer"
+	CatalogManager string = "catalog_manager"
+	OrderOperator  string = "order_operator"
+	SupportAgent   string = "support_agent"
+	WarehouseStaff string = "warehouse_staff"
 )
 
 type User struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"` // Use UUID as the primary key
 	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
 	Email     string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
 	Password  string    `gorm:"type:varchar(100);not null" json:"password"`
 	Role      string    `gorm:"default:customer" json:"role"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	// TokenVersion is embedded in every access token; bumping it invalidates all of them
+	TokenVersion int        `gorm:"not null;default:0" json:"-"`
+	VerifiedAt   *time.Time `json:"verified_at"`
+	// TOTPSecret is set during enrollment and only active once TwoFactorEnabledAt is set
+	TOTPSecret         string     `gorm:"type:varchar(64)" json:"-"`
+	TOTPLastUsedStep   int64      `gorm:"not null;default:0" json:"-"`
+	TwoFactorEnabledAt *time.Time `json:"two_factor_enabled_at"`
+	// SuspendedAt blocks logins and every token of the user while set
+	SuspendedAt      *time.Time `json:"suspended_at"`
+	SuspensionReason string     `gorm:"type:text" json:"suspension_reason"`
+	// PasswordResetRequired blocks password logins until the password is reset by email
+	PasswordResetRequired bool `gorm:"not null;default:false" json:"password_reset_required"`
+	// AnonymizedAt is set when the account was deleted; orders and payments are kept for accounting
+	AnonymizedAt *time.Time `json:"anonymized_at"`
 }
 
 type SignUpInput struct {
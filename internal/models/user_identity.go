@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity links a user to an account at an external OpenID Connect provider
+type UserIdentity struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"` // Use UUID as the primary key
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	UserRefer   uuid.UUID `json:"user_id" gorm:"type:uuid;index;not null"`
+	User        User      `gorm:"foreignKey:UserRefer" json:"-"`
+	Provider    string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identities_provider_subject" json:"provider"`
+	Subject     string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject" json:"subject"`
+	Email       string    `gorm:"type:varchar(100)" json:"email"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+func (userIdentity *UserIdentity) BeforeCreate(tx *gorm.DB) (err error) {
+	// Generate a new UUID and assign it to the ID field
+	userIdentity.ID = uuid.New()
+	return
+}
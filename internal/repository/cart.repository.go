@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CartRepository stores the carts of users and their items
+type CartRepository interface {
+	FindByUser(userID uuid.UUID) (*models.Cart, error)
+	Create(cart *models.Cart) error
+	Save(cart *models.Cart) error
+	// Total sums the price of the items of a cart at the current product prices
+	Total(cartID uuid.UUID) (float64, error)
+
+	// ListItems returns a page of the items of a cart with their product, and
+	// the number of items of the cart
+	ListItems(cartID uuid.UUID, page Page) ([]models.CartItem, int64, error)
+	// FindItem returns a cart item with its product
+	FindItem(id uuid.UUID) (*models.CartItem, error)
+	FindItemByProduct(cartID, productID uuid.UUID) (*models.CartItem, error)
+	CreateItem(item *models.CartItem) error
+	SaveItem(item *models.CartItem) error
+	DeleteItem(item *models.CartItem) error
+	// Clear removes every item of a cart
+	Clear(cartID uuid.UUID) error
+}
+
+type cartRepository struct {
+	db *gorm.DB
+}
+
+func (r *cartRepository) FindByUser(userID uuid.UUID) (*models.Cart, error) {
+	var cart models.Cart
+	if err := first(r.db, &cart, "user_refer = ?", userID); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (r *cartRepository) Create(cart *models.Cart) error {
+	return r.db.Omit(clause.Associations).Create(cart).Error
+}
+
+func (r *cartRepository) Save(cart *models.Cart) error {
+	return r.db.Omit(clause.Associations).Save(cart).Error
+}
+
+func (r *cartRepository) Total(cartID uuid.UUID) (float64, error) {
+	var total float64
+	err := r.db.Model(&models.CartItem{}).
+		Where("cart_items.cart_refer = ?", cartID).
+		Select("COALESCE(SUM(cart_items.quantity * products.price), 0)"). // Use COALESCE to handle NULL
+		Joins("JOIN products ON cart_items.product_refer = products.id").
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *cartRepository) ListItems(cartID uuid.UUID, page Page) ([]models.CartItem, int64, error) {
+	query := r.db.Model(&models.CartItem{}).Where("cart_refer = ?", cartID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var items []models.CartItem
+	if err := query.Scopes(page.scope).Preload("Product").Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *cartRepository) FindItem(id uuid.UUID) (*models.CartItem, error) {
+	var item models.CartItem
+	if err := first(r.db.Preload("Product"), &item, "id = ?", id); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *cartRepository) FindItemByProduct(cartID, productID uuid.UUID) (*models.CartItem, error) {
+	var item models.CartItem
+	if err := first(r.db, &item, "cart_refer = ? AND product_refer = ?", cartID, productID); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *cartRepository) CreateItem(item *models.CartItem) error {
+	return r.db.Omit(clause.Associations).Create(item).Error
+}
+
+func (r *cartRepository) SaveItem(item *models.CartItem) error {
+	return r.db.Omit(clause.Associations).Save(item).Error
+}
+
+func (r *cartRepository) DeleteItem(item *models.CartItem) error {
+	return r.db.Delete(item).Error
+}
+
+func (r *cartRepository) Clear(cartID uuid.UUID) error {
+	return r.db.Where("cart_refer = ?", cartID).Delete(&models.CartItem{}).Error
+}
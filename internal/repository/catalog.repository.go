@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProductFilter narrows a product list, every product when empty
+type ProductFilter struct {
+	CategoryID *uuid.UUID
+}
+
+// CatalogRepository stores products and their categories
+type CatalogRepository interface {
+	// ListProducts returns a page of products with their category, and the
+	// number of products matching the filter
+	ListProducts(filter ProductFilter, page Page) ([]models.Product, int64, error)
+	// FindProduct returns a product with its category
+	FindProduct(id uuid.UUID) (*models.Product, error)
+	CreateProduct(product *models.Product) error
+	SaveProduct(product *models.Product) error
+	DeleteProduct(product *models.Product) error
+
+	ListCategories(page Page) ([]models.Category, int64, error)
+	FindCategory(id uuid.UUID) (*models.Category, error)
+	CreateCategory(category *models.Category) error
+	SaveCategory(category *models.Category) error
+	DeleteCategory(category *models.Category) error
+}
+
+type catalogRepository struct {
+	db *gorm.DB
+}
+
+func (r *catalogRepository) ListProducts(filter ProductFilter, page Page) ([]models.Product, int64, error) {
+	query := r.db.Model(&models.Product{})
+	if filter.CategoryID != nil {
+		query = query.Where("category_refer = ?", *filter.CategoryID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var products []models.Product
+	if err := query.Scopes(page.scope).Preload("Category").Find(&products).Error; err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+func (r *catalogRepository) FindProduct(id uuid.UUID) (*models.Product, error) {
+	var product models.Product
+	if err := first(r.db.Preload("Category"), &product, "id = ?", id); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *catalogRepository) CreateProduct(product *models.Product) error {
+	return r.db.Omit(clause.Associations).Create(product).Error
+}
+
+// SaveProduct leaves the category alone, a preloaded one may be stale once
+// the product moved to another category
+func (r *catalogRepository) SaveProduct(product *models.Product) error {
+	return r.db.Omit(clause.Associations).Save(product).Error
+}
+
+func (r *catalogRepository) DeleteProduct(product *models.Product) error {
+	return r.db.Delete(product).Error
+}
+
+func (r *catalogRepository) ListCategories(page Page) ([]models.Category, int64, error) {
+	query := r.db.Model(&models.Category{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var categories []models.Category
+	if err := query.Scopes(page.scope).Find(&categories).Error; err != nil {
+		return nil, 0, err
+	}
+	return categories, total, nil
+}
+
+func (r *catalogRepository) FindCategory(id uuid.UUID) (*models.Category, error) {
+	var category models.Category
+	if err := first(r.db, &category, "id = ?", id); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *catalogRepository) CreateCategory(category *models.Category) error {
+	return r.db.Create(category).Error
+}
+
+func (r *catalogRepository) SaveCategory(category *models.Category) error {
+	return r.db.Save(category).Error
+}
+
+func (r *catalogRepository) DeleteCategory(category *models.Category) error {
+	return r.db.Delete(category).Error
+}
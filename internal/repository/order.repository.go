@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrderRepository stores orders and their items
+type OrderRepository interface {
+	Create(order *models.Order) error
+	CreateItem(item *models.OrderItem) error
+	FindByID(id uuid.UUID) (*models.Order, error)
+	Save(order *models.Order) error
+	// ListByUser returns every order of a user
+	ListByUser(userID uuid.UUID) ([]models.Order, error)
+	// List returns a page of the orders of every user, newest first, and the
+	// number of orders
+	List(page Page) ([]models.Order, int64, error)
+}
+
+type orderRepository struct {
+	db *gorm.DB
+}
+
+func (r *orderRepository) Create(order *models.Order) error {
+	return r.db.Omit(clause.Associations).Create(order).Error
+}
+
+func (r *orderRepository) CreateItem(item *models.OrderItem) error {
+	return r.db.Omit(clause.Associations).Create(item).Error
+}
+
+func (r *orderRepository) FindByID(id uuid.UUID) (*models.Order, error) {
+	var order models.Order
+	if err := first(r.db, &order, "id = ?", id); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *orderRepository) Save(order *models.Order) error {
+	return r.db.Omit(clause.Associations).Save(order).Error
+}
+
+func (r *orderRepository) ListByUser(userID uuid.UUID) ([]models.Order, error) {
+	var orders []models.Order
+	if err := r.db.Where("user_refer = ?", userID).Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *orderRepository) List(page Page) ([]models.Order, int64, error) {
+	query := r.db.Model(&models.Order{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var orders []models.Order
+	if err := query.Scopes(page.scope).Order("created_at DESC").Find(&orders).Error; err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PaymentRepository stores the payments of orders
+type PaymentRepository interface {
+	Create(payment *models.Payment) error
+	FindByID(id uuid.UUID) (*models.Payment, error)
+	Save(payment *models.Payment) error
+}
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+func (r *paymentRepository) Create(payment *models.Payment) error {
+	return r.db.Omit(clause.Associations).Create(payment).Error
+}
+
+func (r *paymentRepository) FindByID(id uuid.UUID) (*models.Payment, error) {
+	var payment models.Payment
+	if err := first(r.db, &payment, "id = ?", id); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *paymentRepository) Save(payment *models.Payment) error {
+	return r.db.Omit(clause.Associations).Save(payment).Error
+}
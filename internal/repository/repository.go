@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is returned when a lookup matches no record
+var ErrNotFound = errors.New("record not found")
+
+// Store gives access to the repository of each aggregate. A store opened by
+// Transaction runs every call of its repositories in that transaction.
+type Store interface {
+	Users() UserRepository
+	Catalog() CatalogRepository
+	Carts() CartRepository
+	Orders() OrderRepository
+	Payments() PaymentRepository
+	Sessions() SessionRepository
+	// Transaction runs fn in a transaction, committed when fn returns nil and
+	// rolled back otherwise
+	Transaction(fn func(store Store) error) error
+	// WithContext returns a store running its queries with ctx, which carries
+	// the request they are made for to the SQL logs
+	WithContext(ctx context.Context) Store
+}
+
+// Page selects a page of a list, every record when Limit is 0
+type Page struct {
+	Number int
+	Limit  int
+}
+
+func (p Page) scope(db *gorm.DB) *gorm.DB {
+	if p.Limit <= 0 {
+		return db
+	}
+	number := p.Number
+	if number < 1 {
+		number = 1
+	}
+	return db.Offset((number - 1) * p.Limit).Limit(p.Limit)
+}
+
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewStore returns a store backed by a GORM connection
+func NewStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+func (s *gormStore) Users() UserRepository       { return &userRepository{db: s.db} }
+func (s *gormStore) Catalog() CatalogRepository  { return &catalogRepository{db: s.db} }
+func (s *gormStore) Carts() CartRepository       { return &cartRepository{db: s.db} }
+func (s *gormStore) Orders() OrderRepository     { return &orderRepository{db: s.db} }
+func (s *gormStore) Payments() PaymentRepository { return &paymentRepository{db: s.db} }
+func (s *gormStore) Sessions() SessionRepository { return &sessionRepository{db: s.db} }
+
+func (s *gormStore) WithContext(ctx context.Context) Store {
+	return &gormStore{db: s.db.WithContext(ctx)}
+}
+
+func (s *gormStore) Transaction(fn func(store Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx})
+	})
+}
+
+// first loads the record matching the conditions into dest, returning
+// ErrNotFound when there is none
+func first(db *gorm.DB, dest interface{}, conds ...interface{}) error {
+	err := db.First(dest, conds...).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SessionRepository stores the sessions of users and the tokens issued for them
+type SessionRepository interface {
+	// ListActive returns the sessions of the user that are neither revoked nor
+	// expired, most recently used first
+	ListActive(userID uuid.UUID) ([]models.Session, error)
+	Create(session *models.Session) error
+	// Revoke ends an active session of the user and revokes its refresh
+	// tokens, returning ErrNotFound when there is no such session
+	Revoke(userID, sessionID uuid.UUID) error
+
+	CreateRefreshToken(token *models.RefreshToken) error
+	// RevokeRefreshToken revokes the refresh token of the user with the hash
+	RevokeRefreshToken(userID uuid.UUID, tokenHash string) error
+	// RevokeAccessToken lists an access token as revoked until it expires
+	RevokeAccessToken(token *models.RevokedToken) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func (r *sessionRepository) ListActive(userID uuid.UUID) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.
+		Where("user_refer = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_active_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *sessionRepository) Create(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepository) Revoke(userID, sessionID uuid.UUID) error {
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND user_refer = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return r.db.Model(&models.RefreshToken{}).
+		Where("session_refer = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *sessionRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *sessionRepository) RevokeRefreshToken(userID uuid.UUID, tokenHash string) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("token_hash = ? AND user_refer = ? AND revoked_at IS NULL", tokenHash, userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *sessionRepository) RevokeAccessToken(token *models.RevokedToken) error {
+	// Expired entries are useless since the token would be rejected anyway
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&models.RevokedToken{}).Error; err != nil {
+		return err
+	}
+	return r.db.Where(models.RevokedToken{TokenID: token.TokenID}).FirstOrCreate(token).Error
+}
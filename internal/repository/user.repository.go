@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserRepository stores user accounts
+type UserRepository interface {
+	FindByID(id uuid.UUID) (*models.User, error)
+	FindByEmail(email string) (*models.User, error)
+	Create(user *models.User) error
+	// UpdateName and UpdatePassword only write their column, so they cannot
+	// undo a concurrent suspension, role change or token revocation
+	UpdateName(id uuid.UUID, name string) error
+	UpdatePassword(id uuid.UUID, passwordHash string) error
+	// ReplacePassword writes the password only while the user still has the
+	// current hash, returning ErrNotFound when it changed meanwhile
+	ReplacePassword(id uuid.UUID, currentHash, passwordHash string) error
+	// RevokeTokens signs the user out of every session by bumping its token
+	// version and revoking its sessions and refresh tokens
+	RevokeTokens(userID uuid.UUID) error
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+func (r *userRepository) FindByID(id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := first(r.db, &user, "id = ?", id); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := first(r.db, &user, "email = ?", email); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) Create(user *models.User) error {
+	return r.db.Create(user).Error
+}
+
+func (r *userRepository) UpdateName(id uuid.UUID, name string) error {
+	return r.updateColumns(id, map[string]interface{}{"name": name})
+}
+
+func (r *userRepository) UpdatePassword(id uuid.UUID, passwordHash string) error {
+	return r.updateColumns(id, map[string]interface{}{"password": passwordHash})
+}
+
+func (r *userRepository) ReplacePassword(id uuid.UUID, currentHash, passwordHash string) error {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND password = ?", id, currentHash).
+		Update("password", passwordHash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// updateColumns writes columns of the user, returning ErrNotFound when there
+// is no such user
+func (r *userRepository) updateColumns(id uuid.UUID, columns map[string]interface{}) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", id).Updates(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) RevokeTokens(userID uuid.UUID) error {
+	if err := r.db.Model(&models.User{}).
+		Where("id = ?", userID).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+		return err
+	}
+
+	if err := r.db.Model(&models.Session{}).
+		Where("user_refer = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+
+	return r.db.Model(&models.RefreshToken{}).
+		Where("user_refer = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/passhash"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCannotManageSelf = errors.New("you cannot manage your own account")
+	ErrStaffAccount     = errors.New("only admins can manage staff accounts")
+)
+
+// FilterUsers narrows a user query to names or emails containing search and to a role.
+// Empty values do not filter.
+func FilterUsers(query *gorm.DB, search, role string) *gorm.DB {
+	if search != "" {
+		pattern := "%" + escapeLike(strings.ToLower(search)) + "%"
+		query = query.Where(`LOWER(name) LIKE ? ESCAPE '\' OR LOWER(email) LIKE ? ESCAPE '\'`, pattern, pattern)
+	}
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	return query
+}
+
+// GetUsers lists users, newest first
+func GetUsers(users *[]models.User, query *gorm.DB) error {
+	return query.Order("created_at DESC").Find(users).Error
+}
+
+// SuspendUser blocks the user from logging in and signs them out everywhere.
+func SuspendUser(userID, actorID uuid.UUID, actorRole, reason string) (*models.User, error) {
+	return manageUser(userID, actorID, actorRole, func(user *models.User, tx *gorm.DB) error {
+		now := time.Now()
+		user.SuspendedAt = &now
+		user.SuspensionReason = reason
+		if err := tx.Model(user).Updates(map[string]interface{}{"suspended_at": now, "suspension_reason": reason}).Error; err != nil {
+			return err
+		}
+		return RevokeAllUserTokens(user.ID, tx)
+	})
+}
+
+// ReactivateUser lifts a suspension.
+func ReactivateUser(userID, actorID uuid.UUID, actorRole string) (*models.User, error) {
+	return manageUser(userID, actorID, actorRole, func(user *models.User, tx *gorm.DB) error {
+		user.SuspendedAt = nil
+		user.SuspensionReason = ""
+		return tx.Model(user).Updates(map[string]interface{}{"suspended_at": nil, "suspension_reason": ""}).Error
+	})
+}
+
+// ForcePasswordReset signs the user out everywhere, blocks password logins
+// until the password is changed and emails them a reset link.
+func ForcePasswordReset(userID, actorID uuid.UUID, actorRole string) (*models.User, error) {
+	user, err := manageUser(userID, actorID, actorRole, func(user *models.User, tx *gorm.DB) error {
+		user.PasswordResetRequired = true
+		if err := tx.Model(user).Update("password_reset_required", true).Error; err != nil {
+			return err
+		}
+		return RevokeAllUserTokens(user.ID, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, sendPasswordReset(user)
+}
+
+// manageUser loads the user, checks the actor may manage them and applies
+// update in a transaction.
+func manageUser(userID, actorID uuid.UUID, actorRole string, update func(user *models.User, tx *gorm.DB) error) (*models.User, error) {
+	if userID == actorID {
+		return nil, ErrCannotManageSelf
+	}
+
+	var user models.User
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return ErrUserNotFound
+		}
+		// Staff accounts may only be managed by whoever can assign roles
+		if user.Role != models.Customer && !models.RoleHasPermission(actorRole, models.PermRoleAssign) {
+			return ErrStaffAccount
+		}
+		return update(&user, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// CreateAdminUser creates a verified admin account, used to bootstrap an
+// instance from the command line.
+func CreateAdminUser(name, email, password string) (*models.User, error) {
+	var existing models.User
+	if err := database.Database.Db.Where("email = ?", email).First(&existing).Error; err == nil {
+		return nil, ErrEmailExists
+	}
+
+	passwordHash, err := passhash.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user := &models.User{
+		Name:       name,
+		Email:      email,
+		Password:   passwordHash,
+		Role:       models.Admin,
+		VerifiedAt: &now,
+	}
+	if err := database.Database.Db.Create(user).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetUserPassword replaces the password of the account with the given email,
+// signs it out everywhere and lifts its login lockout. It is meant for
+// operators recovering an account, e.g. the only admin's.
+func SetUserPassword(email, password string) (*models.User, error) {
+	var user models.User
+	if err := database.Database.Db.Where("email = ? AND anonymized_at IS NULL", email).First(&user).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	passwordHash, err := passhash.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+	err = database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"password":                passwordHash,
+			"password_reset_required": false,
+		}).Error; err != nil {
+			return err
+		}
+		return RevokeAllUserTokens(user.ID, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	clearLoginFailures(email)
+	return &user, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	apiKeyPrefix       = "sk_"
+	apiKeyDisplayChars = 12
+	// apiKeyUsageInterval limits how often last-used tracking writes to the database
+	apiKeyUsageInterval = time.Minute
+)
+
+var (
+	ErrAPIKeyNotFound   = errors.New("api key not found")
+	ErrInvalidAPIKey    = errors.New("invalid, expired or revoked api key")
+	ErrAPIKeyForbidden  = errors.New("api key is missing the required scope")
+	ErrInvalidAPIKeyTTL = errors.New("api key expiry must be in the future")
+)
+
+// CreateAPIKey stores a new key and returns it together with the plain key,
+// which cannot be retrieved again.
+func CreateAPIKey(name string, scopes []string, expiresAt *time.Time, createdBy uuid.UUID) (*models.APIKey, string, error) {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", ErrInvalidAPIKeyTTL
+	}
+
+	key, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	apiKey := models.APIKey{
+		Name:           name,
+		Prefix:         key[:apiKeyDisplayChars],
+		KeyHash:        utils.HashToken(key),
+		Scopes:         strings.Join(scopes, " "),
+		CreatedByRefer: createdBy,
+		ExpiresAt:      expiresAt,
+	}
+	if err := database.Database.Db.Create(&apiKey).Error; err != nil {
+		return nil, "", err
+	}
+	return &apiKey, key, nil
+}
+
+// GetAPIKeys lists API keys, newest first
+func GetAPIKeys(apiKeys *[]models.APIKey, query *gorm.DB) error {
+	return query.Order("created_at DESC").Find(apiKeys).Error
+}
+
+// RotateAPIKey replaces the secret of an active key while keeping its name,
+// scopes and expiry. The previous key stops working immediately.
+func RotateAPIKey(id uuid.UUID) (*models.APIKey, string, error) {
+	var apiKey models.APIKey
+	if err := database.Database.Db.First(&apiKey, "id = ? AND revoked_at IS NULL", id).Error; err != nil {
+		return nil, "", ErrAPIKeyNotFound
+	}
+
+	key, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	apiKey.Prefix = key[:apiKeyDisplayChars]
+	apiKey.KeyHash = utils.HashToken(key)
+	if err := database.Database.Db.Model(&apiKey).Updates(map[string]interface{}{
+		"prefix":   apiKey.Prefix,
+		"key_hash": apiKey.KeyHash,
+	}).Error; err != nil {
+		return nil, "", err
+	}
+	return &apiKey, key, nil
+}
+
+// RevokeAPIKey permanently disables a key.
+func RevokeAPIKey(id uuid.UUID) error {
+	result := database.Database.Db.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// AuthenticateAPIKey resolves a plain key to an active API key and records its usage.
+func AuthenticateAPIKey(key, ip string) (*models.APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	var apiKey models.APIKey
+	if err := database.Database.Db.Where("key_hash = ?", utils.HashToken(key)).First(&apiKey).Error; err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	now := time.Now()
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt)) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyUsageInterval {
+		// Not worth failing the request over, the timestamp is informational
+		database.Database.Db.Model(&models.APIKey{}).
+			Where("id = ?", apiKey.ID).
+			UpdateColumns(map[string]interface{}{"last_used_at": now, "last_used_ip": ip})
+		apiKey.LastUsedAt = &now
+		apiKey.LastUsedIP = ip
+	}
+	return &apiKey, nil
+}
+
+func generateAPIKey() (string, error) {
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + secret, nil
+}
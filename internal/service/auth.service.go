@@ -1,29 +1,52 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log/slog"
 
-	"github.com/arsyaputraa/go-synapsis-challenge/database"
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/passhash"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/tracing"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/google/uuid"
 )
 
 var (
 	ErrUserNotFound    = errors.New("wrong email or password")
 	ErrInvalidPassword = errors.New("wrong email or password")
 	ErrEmailExists     = errors.New("email already exists")
+	// Only returned once the password is known to be correct
+	ErrAccountSuspended      = errors.New("account is suspended")
+	ErrPasswordResetRequired = errors.New("a password reset is required, check your email for the reset link")
 )
 
-// RegisterUser registers a new user in the database.
-func RegisterUser(name, email, password string) (*models.User, error) {
+// AuthService signs users up and in and hands out their tokens
+type AuthService struct {
+	store repository.Store
+}
+
+func NewAuthService(store repository.Store) *AuthService {
+	return &AuthService{store: store}
+}
+
+// Register registers a new user in the database.
+func (s *AuthService) Register(ctx context.Context, name, email, password string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Register")
+	defer span.End()
+
+	users := s.store.WithContext(ctx).Users()
+
 	// Check if the user already exists
-	var existingUser models.User
-	if err := database.Database.Db.Where("email = ?", email).First(&existingUser).Error; err == nil {
+	if _, err := users.FindByEmail(email); err == nil {
 		return nil, ErrEmailExists
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
 	}
 
 	// Hash the password
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	passwordHash, err := passhash.Hash(password)
 	if err != nil {
 		return nil, err
 	}
@@ -32,31 +55,125 @@ func RegisterUser(name, email, password string) (*models.User, error) {
 	user := &models.User{
 		Name:     name,
 		Email:    email,
-		Password: string(passwordHash),
+		Password: passwordHash,
 		Role:     models.Customer,
 	}
 
 	// Save the user in the database
-	if err := database.Database.Db.Create(user).Error; err != nil {
+	if err := users.Create(user); err != nil {
 		return nil, err
 	}
 
+	// A failed delivery must not fail the registration, the user can ask for a new link
+	if err := SendVerificationEmail(user); err != nil {
+		slog.Error("failed to send verification email", "user_id", user.ID, "error", err)
+	}
+
 	return user, nil
 }
 
-// AuthenticateUser authenticates a user with email and password.
-func AuthenticateUser(email, password string) (*models.User, error) {
-	var user models.User
+// Authenticate authenticates a user with email and password. Failed attempts
+// are counted per account and per client IP, and throttled or locked-out
+// callers get a *LoginThrottledError before any password check.
+func (s *AuthService) Authenticate(ctx context.Context, email, password, ip string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Authenticate")
+	defer span.End()
+
+	if err := checkLoginAllowed(email, ip); err != nil {
+		return nil, err
+	}
+
+	users := s.store.WithContext(ctx).Users()
 
 	// Find the user by email
-	if err := database.Database.Db.Where("email = ?", email).First(&user).Error; err != nil {
+	user, err := users.FindByEmail(email)
+	if err != nil {
+		recordLoginFailure(email, ip)
 		return nil, ErrUserNotFound
 	}
 
 	// Compare the hashed password with the provided password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+	match, rehash, err := passhash.Verify(user.Password, password)
+	if err != nil || !match {
+		recordLoginFailure(email, ip)
 		return nil, ErrInvalidPassword
 	}
+	if rehash {
+		upgradePasswordHash(users, user, password)
+	}
+
+	// With two-factor enabled the login is only complete after the second step,
+	// clearing here would let wrong codes be retried without ever locking
+	if user.TwoFactorEnabledAt == nil {
+		clearLoginFailures(email)
+	}
+
+	if user.SuspendedAt != nil {
+		return nil, ErrAccountSuspended
+	}
+	if user.PasswordResetRequired {
+		return nil, ErrPasswordResetRequired
+	}
+	return user, nil
+}
+
+// IssueTokens starts a new session for the user and returns its access token
+// and persisted refresh token. mfa records whether the user completed a second factor.
+func (s *AuthService) IssueTokens(ctx context.Context, user *models.User, mfa bool, client ClientInfo) (*TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.IssueTokens")
+	defer span.End()
+
+	var pair *TokenPair
+	err := s.store.WithContext(ctx).Transaction(func(store repository.Store) error {
+		var err error
+		pair, err = issueTokenPair(user, mfa, client, store.Sessions())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Logout ends the session of the access token, revoking the token itself and
+// the refresh tokens issued with it. refreshToken, when given, is revoked too.
+func (s *AuthService) Logout(ctx context.Context, userID uuid.UUID, claims *utils.Claims, refreshToken string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.Logout")
+	defer span.End()
 
-	return &user, nil
+	return s.store.WithContext(ctx).Transaction(func(store repository.Store) error {
+		sessions := store.Sessions()
+		if refreshToken != "" {
+			if err := sessions.RevokeRefreshToken(userID, utils.HashToken(refreshToken)); err != nil {
+				return err
+			}
+		}
+		// Ending the session also revokes the refresh tokens issued with it
+		if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+			if err := sessions.Revoke(userID, sessionID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return err
+			}
+		}
+		return revokeAccessToken(claims, sessions)
+	})
+}
+
+// upgradePasswordHash rehashes the password with the current algorithm and
+// parameters. A failure only leaves the old hash in place.
+func upgradePasswordHash(users repository.UserRepository, user *models.User, password string) {
+	passwordHash, err := passhash.Hash(password)
+	if err != nil {
+		slog.Error("failed to rehash password", "user_id", user.ID, "error", err)
+		return
+	}
+	// Skip the update if the password changed meanwhile
+	err = users.ReplacePassword(user.ID, user.Password, passwordHash)
+	if errors.Is(err, repository.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		slog.Error("failed to rehash password", "user_id", user.ID, "error", err)
+		return
+	}
+	user.Password = passwordHash
 }
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// background tracks the work started by runInBackground
+var background sync.WaitGroup
+
+// runInBackground runs fn without making the caller wait for it, for work
+// whose duration must not show in the response time, such as emailing links
+// to registered addresses only
+func runInBackground(name string, fn func()) {
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("background work panicked", "work", name, "panic", r)
+			}
+		}()
+		fn()
+	}()
+}
+
+// WaitBackground waits until the work started in the background is done, or
+// ctx is. It is called on shutdown so that no email is lost.
+func WaitBackground(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
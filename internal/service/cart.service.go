@@ -1,67 +1,177 @@
 package service
 
 import (
-	"fmt"
+	"context"
+	"errors"
 
-	"github.com/arsyaputraa/go-synapsis-challenge/database"
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/metrics"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/tracing"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
-func FindCartByUserId(cart *models.Cart, userID *uuid.UUID, query *gorm.DB) error {
-	if err := query.First(cart, "user_refer = ?", userID).Error; err != nil {
-		query.Rollback()
-		return err
-	}
-	return nil
+var (
+	ErrCartNotFound      = errors.New("cart not found")
+	ErrCartItemNotFound  = errors.New("cart item not found")
+	ErrInsufficientStock = errors.New("not enough stock available for this product")
+)
+
+// CartService manages the shopping cart of each user
+type CartService struct {
+	store repository.Store
 }
 
-func ClearCart(cartID uuid.UUID, tx *gorm.DB) error {
-	if err := tx.Where("cart_refer = ?", cartID).Delete(&models.CartItem{}).Error; err != nil {
-		return fmt.Errorf("error clearing cart: %w", err)
-	}
-	return nil
+func NewCartService(store repository.Store) *CartService {
+	return &CartService{store: store}
 }
 
-func FindOrCreateCartByUserId(cart *models.Cart, userID *uuid.UUID) error {
+// AddToCart adds quantity of a product to the cart of a user, creating the
+// cart on first use
+func (s *CartService) AddToCart(ctx context.Context, userID, productID uuid.UUID, quantity int) error {
+	ctx, span := tracing.Start(ctx, "CartService.AddToCart")
+	defer span.End()
 
-	if err := database.Database.Db.Where("user_refer = ?", userID).First(&cart).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			newCart := models.Cart{
-				UserRefer: *userID,
-			}
-			if err := database.Database.Db.Create(&newCart).Error; err != nil {
-				return err
+	var cartCreated bool
+	err := s.store.WithContext(ctx).Transaction(func(store repository.Store) error {
+		product, err := store.Catalog().FindProduct(productID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrProductNotFound
 			}
-			*cart = newCart
-		} else {
 			return err
 		}
+
+		// Check product stock
+		if product.Stock < quantity {
+			metrics.StockOuts.WithLabelValues("cart").Inc()
+			return ErrInsufficientStock
+		}
+
+		var cart *models.Cart
+		if cart, cartCreated, err = findOrCreateCart(store, userID); err != nil {
+			return err
+		}
+
+		cartItem, err := store.Carts().FindItemByProduct(cart.ID, product.ID)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			cartItem = &models.CartItem{CartRefer: cart.ID, ProductRefer: product.ID, Quantity: quantity}
+			err = store.Carts().CreateItem(cartItem)
+		case err == nil:
+			cartItem.Quantity += quantity
+			err = store.Carts().SaveItem(cartItem)
+		}
+		if err != nil {
+			return err
+		}
+
+		return updateCartTotal(ctx, store, cart)
+	})
+	if err == nil && cartCreated {
+		metrics.CartsCreated.Inc()
 	}
-	return nil
+	return err
 }
 
-func GetCartItemsListByCartId(cartItems *[]models.CartItem, cartID *uuid.UUID, query *gorm.DB) error {
+// GetCart returns the cart of a user with a page of its items and the number
+// of items, creating the cart on first use
+func (s *CartService) GetCart(ctx context.Context, userID uuid.UUID, page repository.Page) (*models.Cart, []models.CartItem, int64, error) {
+	ctx, span := tracing.Start(ctx, "CartService.GetCart")
+	defer span.End()
 
-	if err := query.Where("cart_refer = ?", *cartID).Preload("Product").Find(&cartItems).Error; err != nil {
+	var (
+		cart    *models.Cart
+		items   []models.CartItem
+		total   int64
+		created bool
+	)
+	err := s.store.WithContext(ctx).Transaction(func(store repository.Store) error {
+		var err error
+		if cart, created, err = findOrCreateCart(store, userID); err != nil {
+			return err
+		}
+		items, total, err = store.Carts().ListItems(cart.ID, page)
 		return err
+	})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if created {
+		metrics.CartsCreated.Inc()
+	}
+	return cart, items, total, nil
+}
+
+// GetCartItem retrieves a cart item with its product
+func (s *CartService) GetCartItem(ctx context.Context, cartItemID uuid.UUID) (*models.CartItem, error) {
+	ctx, span := tracing.Start(ctx, "CartService.GetCartItem")
+	defer span.End()
+
+	cartItem, err := s.store.WithContext(ctx).Carts().FindItem(cartItemID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrCartItemNotFound
 	}
+	return cartItem, err
+}
 
-	return nil
+// RemoveCartItem removes an item from the cart of a user
+func (s *CartService) RemoveCartItem(ctx context.Context, userID, cartItemID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "CartService.RemoveCartItem")
+	defer span.End()
+
+	return s.store.WithContext(ctx).Transaction(func(store repository.Store) error {
+		cartItem, err := store.Carts().FindItem(cartItemID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrCartItemNotFound
+			}
+			return err
+		}
+
+		// Only items of the cart of the user can be removed
+		cart, err := store.Carts().FindByUser(userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrCartNotFound
+			}
+			return err
+		}
+		if cart.ID != cartItem.CartRefer {
+			return ErrCartNotFound
+		}
+
+		if err := store.Carts().DeleteItem(cartItem); err != nil {
+			return err
+		}
+		return updateCartTotal(ctx, store, cart)
+	})
 }
 
-// SERVICE FUNCTION
-func UpdateCartTotalTransaction(cart *models.Cart, tx *gorm.DB) error {
-	var total float64
-	if err := tx.Model(&models.CartItem{}).
-		Where("cart_refer = ?", cart.ID).
-		Select("COALESCE(SUM(quantity * price), 0)"). // Use COALESCE to handle NULL
-		Joins("JOIN products ON cart_items.product_refer = products.id").
-		Scan(&total).Error; err != nil {
-		return err
+// findOrCreateCart returns the cart of a user, and whether it was created
+func findOrCreateCart(store repository.Store, userID uuid.UUID) (*models.Cart, bool, error) {
+	cart, err := store.Carts().FindByUser(userID)
+	created := errors.Is(err, repository.ErrNotFound)
+	if created {
+		cart = &models.Cart{UserRefer: userID}
+		err = store.Carts().Create(cart)
 	}
+	if err != nil {
+		return nil, false, err
+	}
+	return cart, created, nil
+}
+
+// updateCartTotal recomputes the total of a cart from its items
+func updateCartTotal(ctx context.Context, store repository.Store, cart *models.Cart) error {
+	ctx, span := tracing.Start(ctx, "updateCartTotal")
+	defer span.End()
+	store = store.WithContext(ctx)
 
+	total, err := store.Carts().Total(cart.ID)
+	if err != nil {
+		return err
+	}
 	cart.TotalAmount = total
-	return tx.Save(cart).Error
+	return store.Carts().Save(cart)
 }
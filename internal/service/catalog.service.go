@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/tracing"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrProductNotFound  = errors.New("product not found")
+	ErrCategoryNotFound = errors.New("category not found")
+)
+
+// CatalogService manages the products and categories of the store
+type CatalogService struct {
+	store repository.Store
+}
+
+func NewCatalogService(store repository.Store) *CatalogService {
+	return &CatalogService{store: store}
+}
+
+// GetProducts lists a page of products, of a single category when categoryID
+// is set, and counts the products matching
+func (s *CatalogService) GetProducts(ctx context.Context, categoryID *uuid.UUID, page repository.Page) ([]models.Product, int64, error) {
+	ctx, span := tracing.Start(ctx, "CatalogService.GetProducts")
+	defer span.End()
+
+	return s.store.WithContext(ctx).Catalog().ListProducts(repository.ProductFilter{CategoryID: categoryID}, page)
+}
+
+// GetProductByID retrieves a product by its ID
+func (s *CatalogService) GetProductByID(ctx context.Context, productID uuid.UUID) (*models.Product, error) {
+	ctx, span := tracing.Start(ctx, "CatalogService.GetProductByID")
+	defer span.End()
+
+	product, err := s.store.WithContext(ctx).Catalog().FindProduct(productID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrProductNotFound
+	}
+	return product, err
+}
+
+func (s *CatalogService) CreateProduct(ctx context.Context, product *models.Product) error {
+	ctx, span := tracing.Start(ctx, "CatalogService.CreateProduct")
+	defer span.End()
+
+	return s.store.WithContext(ctx).Catalog().CreateProduct(product)
+}
+
+func (s *CatalogService) UpdateProduct(ctx context.Context, product *models.Product) error {
+	ctx, span := tracing.Start(ctx, "CatalogService.UpdateProduct")
+	defer span.End()
+
+	return s.store.WithContext(ctx).Catalog().SaveProduct(product)
+}
+
+func (s *CatalogService) DeleteProduct(ctx context.Context, product *models.Product) error {
+	ctx, span := tracing.Start(ctx, "CatalogService.DeleteProduct")
+	defer span.End()
+
+	return s.store.WithContext(ctx).Catalog().DeleteProduct(product)
+}
+
+// GetCategories lists a page of categories and counts them all
+func (s *CatalogService) GetCategories(ctx context.Context, page repository.Page) ([]models.Category, int64, error) {
+	ctx, span := tracing.Start(ctx, "CatalogService.GetCategories")
+	defer span.End()
+
+	return s.store.WithContext(ctx).Catalog().ListCategories(page)
+}
+
+func (s *CatalogService) GetCategoryByID(ctx context.Context, categoryID uuid.UUID) (*models.Category, error) {
+	ctx, span := tracing.Start(ctx, "CatalogService.GetCategoryByID")
+	defer span.End()
+
+	category, err := s.store.WithContext(ctx).Catalog().FindCategory(categoryID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrCategoryNotFound
+	}
+	return category, err
+}
+
+func (s *CatalogService) CreateCategory(ctx context.Context, category *models.Category) error {
+	ctx, span := tracing.Start(ctx, "CatalogService.CreateCategory")
+	defer span.End()
+
+	return s.store.WithContext(ctx).Catalog().CreateCategory(category)
+}
+
+func (s *CatalogService) UpdateCategory(ctx context.Context, category *models.Category) error {
+	ctx, span := tracing.Start(ctx, "CatalogService.UpdateCategory")
+	defer span.End()
+
+	return s.store.WithContext(ctx).Catalog().SaveCategory(category)
+}
+
+func (s *CatalogService) DeleteCategory(ctx context.Context, category *models.Category) error {
+	ctx, span := tracing.Start(ctx, "CatalogService.DeleteCategory")
+	defer span.End()
+
+	return s.store.WithContext(ctx).Catalog().DeleteCategory(category)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCannotImpersonate = errors.New("only active customer accounts can be impersonated")
+	ErrImpersonationOver = errors.New("impersonation is no longer allowed")
+)
+
+// ImpersonationTokenTTL is how long an impersonation token can be used
+var ImpersonationTokenTTL = config.Get().Auth.ImpersonationTokenTTL
+
+// StartImpersonation mints an access token acting as the user on behalf of
+// the actor. The token carries both and the event is recorded with the reason.
+func StartImpersonation(userID, actorID uuid.UUID, reason, ip string) (string, *models.User, error) {
+	if userID == actorID {
+		return "", nil, ErrCannotManageSelf
+	}
+
+	var user models.User
+	if err := database.Database.Db.First(&user, "id = ?", userID).Error; err != nil {
+		return "", nil, ErrUserNotFound
+	}
+	// Staff accounts would hand out their permissions
+	if user.Role != models.Customer || user.SuspendedAt != nil || user.AnonymizedAt != nil {
+		return "", nil, ErrCannotImpersonate
+	}
+
+	token, err := utils.GenerateJWTWithTTL(&utils.Claims{
+		UserID:       user.ID.String(),
+		Role:         user.Role,
+		TokenVersion: user.TokenVersion,
+		Actor:        &utils.ActorClaims{UserID: actorID.String()},
+	}, ImpersonationTokenTTL)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := recordSecurityEvent(models.SecurityEvent{
+		Type:      models.EventImpersonationStarted,
+		Email:     user.Email,
+		IP:        ip,
+		UserRefer: &user.ID,
+		Detail:    fmt.Sprintf("impersonated by %s: %s", actorID, reason),
+	}); err != nil {
+		return "", nil, err
+	}
+	return token, &user, nil
+}
+
+// validateImpersonator checks that the actor of an impersonation token may
+// still impersonate, so removing the permission ends running impersonations.
+func validateImpersonator(actorID string) error {
+	var actor models.User
+	if err := database.Database.Db.Select("id", "role", "suspended_at").First(&actor, "id = ?", actorID).Error; err != nil {
+		return ErrImpersonationOver
+	}
+	if actor.SuspendedAt != nil || !models.RoleHasPermission(actor.Role, models.PermUserImpersonate) {
+		return ErrImpersonationOver
+	}
+	return nil
+}
+
+// RecordImpersonatedRequest adds a request to the impersonation audit trail
+func RecordImpersonatedRequest(entry models.ImpersonationLog) error {
+	entry.Path = truncate(entry.Path, 255)
+	return database.Database.Db.Create(&entry).Error
+}
+
+// GetImpersonationLogs lists the impersonation audit trail, newest first
+func GetImpersonationLogs(logs *[]models.ImpersonationLog, query *gorm.DB) error {
+	return query.Order("created_at DESC").Find(logs).Error
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginAttemptStore keeps the failed login counters used by the login guard
+type LoginAttemptStore interface {
+	// Get returns the counters for key, or a zero value when there are none
+	Get(key string) (models.LoginAttempt, error)
+	// RecordFailure increments the failure counter of key. Counters whose last
+	// failure is older than window start again from one.
+	RecordFailure(key string, at time.Time, window time.Duration) (models.LoginAttempt, error)
+	// Lock blocks key until the given time
+	Lock(key string, until time.Time) error
+	// Reset clears the counters and any lock of key
+	Reset(key string) error
+}
+
+// MemoryLoginAttemptStore keeps counters in process memory. Counters are lost on
+// restart and not shared between instances.
+type MemoryLoginAttemptStore struct {
+	mu          sync.Mutex
+	attempts    map[string]models.LoginAttempt
+	lastEvicted time.Time
+}
+
+func NewMemoryLoginAttemptStore() *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{attempts: make(map[string]models.LoginAttempt)}
+}
+
+func (s *MemoryLoginAttemptStore) Get(key string) (models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt, ok := s.attempts[key]
+	if !ok {
+		return models.LoginAttempt{Key: key}, nil
+	}
+	return attempt, nil
+}
+
+func (s *MemoryLoginAttemptStore) RecordFailure(key string, at time.Time, window time.Duration) (models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictExpired(at, window)
+
+	attempt := s.attempts[key]
+	attempt.Key = key
+	if at.Sub(attempt.LastFailureAt) > window {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = at
+	attempt.UpdatedAt = at
+	s.attempts[key] = attempt
+	return attempt, nil
+}
+
+func (s *MemoryLoginAttemptStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt := s.attempts[key]
+	attempt.Key = key
+	attempt.LockedUntil = &until
+	s.attempts[key] = attempt
+	return nil
+}
+
+func (s *MemoryLoginAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+// evictExpired drops counters that can no longer throttle or lock anyone. Like
+// DatabaseLoginAttemptStore.pruneExpired it runs at most once per window, so a
+// burst of failures does not walk every counter on each of them.
+func (s *MemoryLoginAttemptStore) evictExpired(now time.Time, window time.Duration) {
+	if now.Sub(s.lastEvicted) < window {
+		return
+	}
+	s.lastEvicted = now
+
+	for key, attempt := range s.attempts {
+		locked := attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil)
+		if !locked && now.Sub(attempt.LastFailureAt) > window {
+			delete(s.attempts, key)
+		}
+	}
+}
+
+// DatabaseLoginAttemptStore keeps counters in the login_attempts table so they
+// are shared by every instance of the API.
+type DatabaseLoginAttemptStore struct {
+	db *gorm.DB
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+func NewDatabaseLoginAttemptStore(db *gorm.DB) *DatabaseLoginAttemptStore {
+	return &DatabaseLoginAttemptStore{db: db}
+}
+
+func (s *DatabaseLoginAttemptStore) Get(key string) (models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	if err := s.db.Where("attempt_key = ?", key).Limit(1).Find(&attempt).Error; err != nil {
+		return models.LoginAttempt{}, err
+	}
+	attempt.Key = key
+	return attempt, nil
+}
+
+func (s *DatabaseLoginAttemptStore) RecordFailure(key string, at time.Time, window time.Duration) (models.LoginAttempt, error) {
+	if err := s.pruneExpired(at, window); err != nil {
+		return models.LoginAttempt{}, err
+	}
+
+	// A single upsert keeps the increment atomic across concurrent requests
+	attempt := models.LoginAttempt{Key: key, Failures: 1, LastFailureAt: at}
+	err := s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "attempt_key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":        gorm.Expr("CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END", at.Add(-window)),
+			"last_failure_at": at,
+			"updated_at":      at,
+		}),
+	}).Create(&attempt).Error
+	if err != nil {
+		return models.LoginAttempt{}, err
+	}
+	return s.Get(key)
+}
+
+// pruneExpired deletes counters that can no longer throttle or lock anyone. A
+// counter expires a window after its last failure at the earliest, so pruning
+// once per window keeps the table to the counters of about two windows.
+func (s *DatabaseLoginAttemptStore) pruneExpired(now time.Time, window time.Duration) error {
+	s.mu.Lock()
+	if now.Sub(s.lastPruned) < window {
+		s.mu.Unlock()
+		return nil
+	}
+	s.lastPruned = now
+	s.mu.Unlock()
+
+	return s.db.Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", now.Add(-window), now).
+		Delete(&models.LoginAttempt{}).Error
+}
+
+func (s *DatabaseLoginAttemptStore) Lock(key string, until time.Time) error {
+	return s.db.Model(&models.LoginAttempt{}).Where("attempt_key = ?", key).Update("locked_until", until).Error
+}
+
+func (s *DatabaseLoginAttemptStore) Reset(key string) error {
+	return s.db.Where("attempt_key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+var (
+	loginAttempts     LoginAttemptStore
+	loginAttemptsOnce sync.Once
+)
+
+// SetLoginAttemptStore replaces the store used by the login guard, e.g. in tests
+func SetLoginAttemptStore(store LoginAttemptStore) {
+	loginAttemptsOnce.Do(func() {})
+	loginAttempts = store
+}
+
+func loginAttemptStore() LoginAttemptStore {
+	loginAttemptsOnce.Do(func() {
+		if LoginAttemptStoreDriver == "memory" {
+			loginAttempts = NewMemoryLoginAttemptStore()
+			return
+		}
+		loginAttempts = NewDatabaseLoginAttemptStore(database.Database.Db)
+	})
+	return loginAttempts
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+
+var (
+	LoginAttemptStoreDriver = config.Get().Login.AttemptStore
+	// LoginAttemptWindow is how long a failure counts towards throttling and lockout
+	LoginAttemptWindow = config.Get().Login.AttemptWindow
+	// LoginDelayBase is the wait imposed after the first failure, doubled after every further failure
+	LoginDelayBase         = config.Get().Login.DelayBase
+	LoginDelayMax          = config.Get().Login.DelayMax
+	AccountLockoutAttempts = config.Get().Login.AccountLockoutAttempts
+	IPLockoutAttempts      = config.Get().Login.IPLockoutAttempts
+	LockoutDuration        = config.Get().Login.LockoutDuration
+)
+
+// LoginThrottledError is returned while an account or IP is delayed or locked out
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return ErrTooManyLoginAttempts.Error()
+}
+
+func (e *LoginThrottledError) Is(target error) bool {
+	return target == ErrTooManyLoginAttempts
+}
+
+// checkLoginAllowed is called before any password comparison so throttled
+// requests do not cost a bcrypt hash.
+func checkLoginAllowed(email, ip string) error {
+	store := loginAttemptStore()
+	now := time.Now()
+
+	account, err := store.Get(accountAttemptKey(email))
+	if err != nil {
+		return err
+	}
+	if retryAfter := throttleDelay(account, now); retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+
+	if ip == "" {
+		return nil
+	}
+	client, err := store.Get(ipAttemptKey(ip))
+	if err != nil {
+		return err
+	}
+	if client.LockedUntil != nil && now.Before(*client.LockedUntil) {
+		return &LoginThrottledError{RetryAfter: client.LockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed login against the account and the IP,
+// locking either of them once its threshold is reached.
+func recordLoginFailure(email, ip string) {
+	store := loginAttemptStore()
+	now := time.Now()
+
+	account, err := store.RecordFailure(accountAttemptKey(email), now, LoginAttemptWindow)
+	if err != nil {
+		slog.Error("failed to record login failure", "email", email, "error", err)
+	} else if account.Failures >= AccountLockoutAttempts && !isLocked(account, now) {
+		lockLogin(accountAttemptKey(email), models.SecurityEvent{Type: models.EventAccountLocked, Email: normalizeEmail(email), IP: ip,
+			Detail: fmt.Sprintf("%d failed logins within %s", account.Failures, LoginAttemptWindow)})
+	}
+
+	if ip == "" {
+		return
+	}
+	client, err := store.RecordFailure(ipAttemptKey(ip), now, LoginAttemptWindow)
+	if err != nil {
+		slog.Error("failed to record login failure", "ip", ip, "error", err)
+	} else if client.Failures >= IPLockoutAttempts && !isLocked(client, now) {
+		lockLogin(ipAttemptKey(ip), models.SecurityEvent{Type: models.EventIPLocked, Email: normalizeEmail(email), IP: ip,
+			Detail: fmt.Sprintf("%d failed logins within %s", client.Failures, LoginAttemptWindow)})
+	}
+}
+
+// clearLoginFailures resets the account counters after a successful login. IP
+// counters are kept so an attacker cannot reset them with an account of their own.
+func clearLoginFailures(email string) {
+	if err := loginAttemptStore().Reset(accountAttemptKey(email)); err != nil {
+		slog.Error("failed to reset login failures", "email", email, "error", err)
+	}
+}
+
+// UnlockLogin lifts the lockout of an account and/or an IP address.
+func UnlockLogin(email, ip string, adminID uuid.UUID) error {
+	store := loginAttemptStore()
+	if email != "" {
+		if err := store.Reset(accountAttemptKey(email)); err != nil {
+			return err
+		}
+	}
+	if ip != "" {
+		if err := store.Reset(ipAttemptKey(ip)); err != nil {
+			return err
+		}
+	}
+
+	return recordSecurityEvent(models.SecurityEvent{
+		Type:   models.EventLockoutCleared,
+		Email:  normalizeEmail(email),
+		IP:     ip,
+		Detail: fmt.Sprintf("unlocked by admin %s", adminID),
+	})
+}
+
+func lockLogin(key string, event models.SecurityEvent) {
+	if err := loginAttemptStore().Lock(key, time.Now().Add(LockoutDuration)); err != nil {
+		slog.Error("failed to lock login", "attempt_key", key, "error", err)
+		return
+	}
+
+	var user models.User
+	if event.Email != "" && database.Database.Db.Select("id").Where("email = ?", event.Email).Limit(1).Find(&user).Error == nil && user.ID != uuid.Nil {
+		event.UserRefer = &user.ID
+	}
+	if err := recordSecurityEvent(event); err != nil {
+		slog.Error("failed to record security event", "type", event.Type, "error", err)
+	}
+}
+
+func recordSecurityEvent(event models.SecurityEvent) error {
+	return database.Database.Db.Create(&event).Error
+}
+
+// throttleDelay returns how long the caller must wait before the next attempt
+func throttleDelay(attempt models.LoginAttempt, now time.Time) time.Duration {
+	if isLocked(attempt, now) {
+		return attempt.LockedUntil.Sub(now)
+	}
+	if attempt.Failures == 0 || now.Sub(attempt.LastFailureAt) > LoginAttemptWindow {
+		return 0
+	}
+
+	delay := time.Duration(float64(LoginDelayBase) * math.Pow(2, float64(attempt.Failures-1)))
+	if delay > LoginDelayMax {
+		delay = LoginDelayMax
+	}
+	return time.Until(attempt.LastFailureAt.Add(delay))
+}
+
+func isLocked(attempt models.LoginAttempt, now time.Time) bool {
+	return attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil)
+}
+
+func accountAttemptKey(email string) string {
+	return "email:" + normalizeEmail(email)
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// GetSecurityEvents lists security events, newest first
+func GetSecurityEvents(events *[]models.SecurityEvent, query *gorm.DB) error {
+	return query.Order("created_at DESC").Find(events).Error
+}
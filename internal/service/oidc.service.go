@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/oidc"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/passhash"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidOIDCState  = errors.New("invalid or expired login state")
+	ErrOIDCLoginFailed   = errors.New("could not sign in with the identity provider")
+	ErrOIDCEmailRequired = errors.New("the identity provider did not share an email address")
+	ErrOIDCAccountExists = errors.New("an account with this email already exists, log in with your password")
+)
+
+// OIDCStateTTL is how long the user has to sign in at the identity provider
+var OIDCStateTTL = config.Get().Auth.OIDCStateTTL
+
+// OIDCLogin is an OpenID Connect login in progress
+type OIDCLogin struct {
+	// AuthURL is where the user signs in at the identity provider
+	AuthURL string
+	// StateToken must be kept by the client, e.g. in a cookie, until the callback
+	StateToken string
+}
+
+// BeginOIDCLogin starts an authorization code flow with PKCE at the named provider.
+func BeginOIDCLogin(ctx context.Context, providerName string) (*OIDCLogin, error) {
+	provider, err := oidc.Lookup(ctx, providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := utils.GenerateSecureToken(16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := utils.GenerateSecureToken(16)
+	if err != nil {
+		return nil, err
+	}
+	verifier := oidc.GenerateVerifier()
+
+	stateToken, err := utils.GenerateOIDCState(&utils.OIDCStateClaims{
+		Provider:       provider.Name,
+		Nonce:          nonce,
+		Verifier:       verifier,
+		StandardClaims: jwt.StandardClaims{Id: state},
+	}, OIDCStateTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCLogin{AuthURL: provider.AuthCodeURL(state, nonce, verifier), StateToken: stateToken}, nil
+}
+
+// CompleteOIDCLogin redeems the authorization code returned to the callback and
+// returns the linked user, provisioning a customer account on first login.
+func CompleteOIDCLogin(ctx context.Context, providerName, stateToken, state, code string) (*models.User, error) {
+	claims, err := utils.ParseOIDCState(stateToken)
+	if err != nil || claims.Provider != providerName || subtle.ConstantTimeCompare([]byte(claims.Id), []byte(state)) != 1 {
+		return nil, ErrInvalidOIDCState
+	}
+
+	provider, err := oidc.Lookup(ctx, providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := provider.Exchange(ctx, code, claims.Verifier, claims.Nonce)
+	if err != nil {
+		slog.WarnContext(ctx, "oidc login failed", "provider", providerName, "error", err)
+		return nil, ErrOIDCLoginFailed
+	}
+
+	return linkOIDCIdentity(provider, identity)
+}
+
+// linkOIDCIdentity finds the user linked to identity. Unknown identities are
+// linked to a new customer account, or to an existing account with the same
+// email when the provider is trusted to verify emails.
+func linkOIDCIdentity(provider *oidc.Provider, identity *oidc.Identity) (*models.User, error) {
+	var user models.User
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		var link models.UserIdentity
+		if err := tx.Where("provider = ? AND subject = ?", provider.Name, identity.Subject).Limit(1).Find(&link).Error; err != nil {
+			return err
+		}
+		if link.UserRefer != uuid.Nil {
+			if err := tx.First(&user, "id = ?", link.UserRefer).Error; err != nil {
+				return ErrUserNotFound
+			}
+			if user.SuspendedAt != nil {
+				return ErrAccountSuspended
+			}
+			return tx.Model(&link).Updates(map[string]interface{}{"email": identity.Email, "last_login_at": time.Now()}).Error
+		}
+
+		if identity.Email == "" {
+			return ErrOIDCEmailRequired
+		}
+		email := strings.ToLower(identity.Email)
+
+		if err := tx.Where("LOWER(email) = ?", email).Limit(1).Find(&user).Error; err != nil {
+			return err
+		}
+		if user.ID != uuid.Nil {
+			// Linking on email alone would let anyone controlling the provider take over the account
+			if !provider.TrustEmail || !identity.EmailVerified {
+				return ErrOIDCAccountExists
+			}
+			if user.SuspendedAt != nil {
+				return ErrAccountSuspended
+			}
+		} else {
+			created, err := provisionOIDCUser(identity, email, tx)
+			if err != nil {
+				return err
+			}
+			user = *created
+		}
+
+		link = models.UserIdentity{
+			UserRefer:   user.ID,
+			Provider:    provider.Name,
+			Subject:     identity.Subject,
+			Email:       identity.Email,
+			LastLoginAt: time.Now(),
+		}
+		return tx.Create(&link).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// provisionOIDCUser creates a customer account for a first login. The account
+// gets a random password; the user can set one through the password reset flow.
+func provisionOIDCUser(identity *oidc.Identity, email string, tx *gorm.DB) (*models.User, error) {
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := passhash.Hash(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	name := identity.Name
+	if name == "" {
+		name = strings.SplitN(email, "@", 2)[0]
+	}
+
+	user := &models.User{
+		Name:     name,
+		Email:    email,
+		Password: passwordHash,
+		Role:     models.Customer,
+	}
+	if identity.EmailVerified {
+		now := time.Now()
+		user.VerifiedAt = &now
+	}
+	if err := tx.Create(user).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
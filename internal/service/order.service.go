@@ -1,41 +1,176 @@
 package service
 
 import (
-	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"context"
+	"errors"
+	"fmt"
+
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/metrics"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/tracing"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func CreateOrder(order *models.Order, userID *uuid.UUID, totalAmount float64, tx *gorm.DB) error {
-	order.UserRefer = *userID
-	order.Status = string(models.Pending)
-	order.TotalAmount = totalAmount
-	if err := tx.Create(order).Error; err != nil {
-		return err
-	}
-	return nil
+var (
+	ErrCartEmpty     = errors.New("cannot checkout an empty cart")
+	ErrOrderNotFound = errors.New("order not found")
+)
+
+// Checkout is an order placed from a cart, with the payment awaiting the OTP
+type Checkout struct {
+	Order   models.Order
+	Payment models.Payment
+	Otp     string
+}
+
+// OrderService places and lists orders
+type OrderService struct {
+	store    repository.Store
+	payments *PaymentService
+	// requireVerifiedEmail blocks unverified accounts from checking out
+	requireVerifiedEmail bool
+}
+
+func NewOrderService(store repository.Store, payments *PaymentService, requireVerifiedEmail bool) *OrderService {
+	return &OrderService{store: store, payments: payments, requireVerifiedEmail: requireVerifiedEmail}
 }
 
-func GetUserOrders(userID uuid.UUID) ([]models.Order, error) {
-	var orders []models.Order
-	if err := database.Database.Db.Where("user_refer = ?", userID).Find(&orders).Error; err != nil {
+// Checkout turns the cart of a user into an order paid with method: stock is
+// taken, the payment is created and the cart is emptied, all or nothing
+func (s *OrderService) Checkout(ctx context.Context, userID uuid.UUID, method string) (*Checkout, error) {
+	ctx, span := tracing.Start(ctx, "OrderService.Checkout")
+	defer span.End()
+
+	if err := s.checkCheckoutAllowed(ctx, userID); err != nil {
 		return nil, err
 	}
-	return orders, nil
-}
 
-func GetOrderById(orderID uuid.UUID, db *gorm.DB) (*models.Order, error) {
-	var order models.Order
-	if err := db.First(&order, "id = ?", orderID).Error; err != nil {
+	var checkout Checkout
+	err := s.store.WithContext(ctx).Transaction(func(store repository.Store) error {
+		cart, err := store.Carts().FindByUser(userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrCartNotFound
+			}
+			return err
+		}
+
+		cartItems, _, err := store.Carts().ListItems(cart.ID, repository.Page{})
+		if err != nil {
+			return err
+		}
+		if len(cartItems) == 0 {
+			return ErrCartEmpty
+		}
+
+		if err := updateCartTotal(ctx, store, cart); err != nil {
+			return err
+		}
+
+		checkout.Order = models.Order{
+			UserRefer:   userID,
+			Status:      string(models.Pending),
+			TotalAmount: cart.TotalAmount,
+		}
+		if err := store.Orders().Create(&checkout.Order); err != nil {
+			return err
+		}
+
+		for _, cartItem := range cartItems {
+			if err := decrementProductStockByCartItemsQuantity(ctx, store, cartItem, checkout.Order.ID); err != nil {
+				return err
+			}
+		}
+
+		payment, otp, err := s.payments.createPayment(ctx, store, &checkout.Order, method)
+		if err != nil {
+			return err
+		}
+		checkout.Payment, checkout.Otp = *payment, otp
+
+		if err := store.Carts().Clear(cart.ID); err != nil {
+			return fmt.Errorf("error clearing cart: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	return &order, nil
+	metrics.OrdersCheckedOut.Inc()
+	return &checkout, nil
 }
 
-func SaveOrder(order *models.Order, db *gorm.DB) error {
-	if err := db.Save(order).Error; err != nil {
+// checkCheckoutAllowed enforces the verified email policy for checkout
+func (s *OrderService) checkCheckoutAllowed(ctx context.Context, userID uuid.UUID) error {
+	if !s.requireVerifiedEmail {
+		return nil
+	}
+	user, err := s.store.WithContext(ctx).Users().FindByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUserNotFound
+		}
 		return err
 	}
+	if user.VerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// GetUserOrders lists every order of a user
+func (s *OrderService) GetUserOrders(ctx context.Context, userID uuid.UUID) ([]models.Order, error) {
+	ctx, span := tracing.Start(ctx, "OrderService.GetUserOrders")
+	defer span.End()
+
+	return s.store.WithContext(ctx).Orders().ListByUser(userID)
+}
+
+// GetOrders lists a page of the orders of every user, newest first, and
+// counts them all
+func (s *OrderService) GetOrders(ctx context.Context, page repository.Page) ([]models.Order, int64, error) {
+	ctx, span := tracing.Start(ctx, "OrderService.GetOrders")
+	defer span.End()
+
+	return s.store.WithContext(ctx).Orders().List(page)
+}
+
+// decrementProductStockByCartItemsQuantity takes the quantity of a cart item
+// from the stock of its product and adds it to the order
+func decrementProductStockByCartItemsQuantity(ctx context.Context, store repository.Store, cartItem models.CartItem, orderID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "decrementProductStockByCartItemsQuantity", trace.WithAttributes(
+		attribute.String("product.id", cartItem.ProductRefer.String()),
+		attribute.Int("quantity", cartItem.Quantity)))
+	defer span.End()
+	store = store.WithContext(ctx)
+
+	product, err := store.Catalog().FindProduct(cartItem.ProductRefer)
+	if err != nil {
+		return err
+	}
+
+	// Check stock availability
+	if product.Stock < cartItem.Quantity {
+		metrics.StockOuts.WithLabelValues("checkout").Inc()
+		return fmt.Errorf("%w: %s", ErrInsufficientStock, product.Name)
+	}
+
+	// Deduct product stock
+	product.Stock -= cartItem.Quantity
+	if err := store.Catalog().SaveProduct(product); err != nil {
+		return fmt.Errorf("error updating product stock: %w", err)
+	}
+
+	orderItem := models.OrderItem{
+		OrderRefer:      orderID,
+		PriceAtPurchase: product.Price,
+		Quantity:        cartItem.Quantity,
+	}
+	if err := store.Orders().CreateItem(&orderItem); err != nil {
+		return fmt.Errorf("error creating order item: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/mail"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/passhash"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	// Unknown, expired and already used tokens share one error so callers cannot tell them apart
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+)
+
+var PasswordResetTTL = config.Get().Auth.PasswordResetTTL
+
+// RequestPasswordReset emails a one-time reset link to the user. Unknown emails
+// are silently ignored and failed deliveries only logged, and the work runs in
+// the background, so neither the answer nor its timing tells the caller which
+// addresses are registered.
+func RequestPasswordReset(email string) {
+	runInBackground("password reset email", func() {
+		var user models.User
+		if err := database.Database.Db.Where("email = ?", email).First(&user).Error; err != nil {
+			return
+		}
+		if err := sendPasswordReset(&user); err != nil {
+			slog.Error("failed to send password reset email", "user_id", user.ID, "error", err)
+		}
+	})
+}
+
+// sendPasswordReset creates a reset token for the user and emails the link
+func sendPasswordReset(user *models.User) error {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return err
+	}
+
+	resetToken := models.PasswordResetToken{
+		UserRefer: user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(PasswordResetTTL),
+	}
+	if err := database.Database.Db.Create(&resetToken).Error; err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s?token=%s", config.Get().Auth.PasswordResetURL, url.QueryEscape(token))
+	return mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nYour reset token is: %s\n\nThe link expires in %s. If you did not request a reset you can ignore this email.\n",
+			user.Name, link, token, PasswordResetTTL),
+	})
+}
+
+// ResetPassword sets a new password using a reset token. The token is consumed,
+// every other pending reset token is invalidated and the user is signed out everywhere.
+func ResetPassword(token, newPassword string) error {
+	return database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		var resetToken models.PasswordResetToken
+		if err := tx.Where("token_hash = ?", utils.HashToken(token)).First(&resetToken).Error; err != nil {
+			return ErrInvalidResetToken
+		}
+		if resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+			return ErrInvalidResetToken
+		}
+
+		// The conditional update makes the token single-use even under concurrent requests
+		now := time.Now()
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", resetToken.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidResetToken
+		}
+
+		// Older links sent to the same user must not be usable anymore
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_refer = ? AND used_at IS NULL", resetToken.UserRefer).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		var user models.User
+		if err := tx.First(&user, "id = ?", resetToken.UserRefer).Error; err != nil {
+			return ErrInvalidResetToken
+		}
+
+		hashedPassword, err := passhash.Hash(newPassword)
+		if err != nil {
+			return err
+		}
+
+		user.Password = hashedPassword
+		user.PasswordResetRequired = false
+		// The reset link was delivered to the inbox, which proves ownership of the address
+		if user.VerifiedAt == nil {
+			user.VerifiedAt = &now
+		}
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+
+		return RevokeAllUserTokens(user.ID, tx)
+	})
+}
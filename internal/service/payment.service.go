@@ -1,15 +1,17 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
-	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/metrics"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/tracing"
 	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
 )
 
 var (
@@ -17,54 +19,94 @@ var (
 	ErrInvalidPaymentCredential = errors.New("unauthorized payment")
 )
 
-func CreatePayment(order *models.Order, payment *models.Payment, paymentRequest *dto.RequestCreatePayment, otp *string, tx *gorm.DB) error {
+// PaymentService creates the payments of orders and applies the updates of
+// the payment gateway
+type PaymentService struct {
+	store repository.Store
+}
+
+func NewPaymentService(store repository.Store) *PaymentService {
+	return &PaymentService{store: store}
+}
+
+// createPayment creates the unpaid payment of an order and returns it with
+// the OTP the payment gateway must present
+func (s *PaymentService) createPayment(ctx context.Context, store repository.Store, order *models.Order, method string) (*models.Payment, string, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.createPayment")
+	defer span.End()
+	store = store.WithContext(ctx)
 
 	secretCode := utils.GenerateRandomCode(8)
 
+	_, hashSpan := tracing.Start(ctx, "bcrypt.GenerateFromPassword")
 	codeHash, err := bcrypt.GenerateFromPassword([]byte(secretCode), 14)
+	hashSpan.End()
 	if err != nil {
-		return err
+		return nil, "", err
 	}
-	*payment = models.Payment{
+	payment := models.Payment{
 		OrderRefer: order.ID,
 		Status:     string(models.Unpaid),
 		Amount:     order.TotalAmount,
-		Method:     string(paymentRequest.Method),
+		Method:     method,
 		Otp:        string(codeHash),
 	}
-	if err := tx.Create(payment).Error; err != nil {
-		return fmt.Errorf("error creating payment: %w", err)
+	if err := store.Payments().Create(&payment); err != nil {
+		return nil, "", fmt.Errorf("error creating payment: %w", err)
 	}
-	*otp = secretCode
-	return nil
+	return &payment, secretCode, nil
 }
 
-func AuthenticatePayment(id uuid.UUID, otp string, tx *gorm.DB) (*models.Payment, error) {
-	payment, err := FindPaymentByID(id, tx)
-	if err != nil {
-		return nil, err
-	}
-	if err := bcrypt.CompareHashAndPassword([]byte(payment.Otp), []byte(otp)); err != nil {
-		return nil, ErrInvalidPaymentCredential
-	}
-	return payment, nil
-}
+// UpdatePaymentStatus applies a status sent by the payment gateway with the
+// OTP of the payment. A paid payment marks its order as paid.
+func (s *PaymentService) UpdatePaymentStatus(ctx context.Context, paymentID uuid.UUID, status, otp string) error {
+	ctx, span := tracing.Start(ctx, "PaymentService.UpdatePaymentStatus")
+	defer span.End()
 
-func FindPaymentByID(id uuid.UUID, tx *gorm.DB) (*models.Payment, error) {
-	var payment models.Payment
+	err := s.store.WithContext(ctx).Transaction(func(store repository.Store) error {
+		payment, err := authenticatePayment(ctx, store, paymentID, otp)
+		if err != nil {
+			return err
+		}
 
-	if err := tx.Where("id = ?", id).First(&payment).Error; err != nil {
+		payment.Status = status
+		if err := store.Payments().Save(payment); err != nil {
+			return err
+		}
 
-		return nil, ErrPaymentNotFound
+		if status != string(models.Paid) {
+			return nil
+		}
+		order, err := store.Orders().FindByID(payment.OrderRefer)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrOrderNotFound
+			}
+			return err
+		}
+		order.Status = string(models.PaidOrder)
+		return store.Orders().Save(order)
+	})
+	if err == nil && (status == string(models.Paid) || status == string(models.Failed)) {
+		metrics.Payments.WithLabelValues(status).Inc()
 	}
-	return &payment, nil
+	return err
 }
 
-func SavePayment(payment *models.Payment, tx *gorm.DB) error {
-	if err := tx.Save(&payment).Error; err != nil {
-		return err
+func authenticatePayment(ctx context.Context, store repository.Store, id uuid.UUID, otp string) (*models.Payment, error) {
+	ctx, span := tracing.Start(ctx, "authenticatePayment")
+	defer span.End()
+	store = store.WithContext(ctx)
+
+	payment, err := store.Payments().FindByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
 	}
-	return nil
+	if err := bcrypt.CompareHashAndPassword([]byte(payment.Otp), []byte(otp)); err != nil {
+		return nil, ErrInvalidPaymentCredential
+	}
+	return payment, nil
 }
-
-// func FindPaymentByID()(*models.Payment)
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/passhash"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrAccountDeleted = errors.New("account has already been deleted")
+
+// UserData is everything stored about a user, as returned by a data export
+type UserData struct {
+	User           models.User
+	Carts          []models.Cart
+	CartItems      []models.CartItem
+	Orders         []models.Order
+	OrderItems     []models.OrderItem
+	Payments       []models.Payment
+	Sessions       []models.Session
+	Identities     []models.UserIdentity
+	SecurityEvents []models.SecurityEvent
+	APIKeys        []models.APIKey
+}
+
+// ExportUserData collects the personal data held about the user.
+func ExportUserData(userID uuid.UUID) (*UserData, error) {
+	db := database.Database.Db
+	var data UserData
+	if err := db.First(&data.User, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	queries := []struct {
+		dest  interface{}
+		query *gorm.DB
+	}{
+		{&data.Carts, db.Where("user_refer = ?", userID)},
+		{&data.CartItems, db.Preload("Product").Where("cart_refer IN (?)", db.Model(&models.Cart{}).Select("id").Where("user_refer = ?", userID))},
+		{&data.Orders, db.Where("user_refer = ?", userID)},
+		{&data.OrderItems, db.Where("order_refer IN (?)", db.Model(&models.Order{}).Select("id").Where("user_refer = ?", userID))},
+		{&data.Payments, db.Where("order_refer IN (?)", db.Model(&models.Order{}).Select("id").Where("user_refer = ?", userID))},
+		{&data.Sessions, db.Where("user_refer = ?", userID)},
+		{&data.Identities, db.Where("user_refer = ?", userID)},
+		{&data.SecurityEvents, db.Where("user_refer = ? OR email = ?", userID, normalizeEmail(data.User.Email))},
+		{&data.APIKeys, db.Where("created_by_refer = ?", userID)},
+	}
+	for _, q := range queries {
+		if err := q.query.Order("created_at").Find(q.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &data, nil
+}
+
+// DeleteOwnAccount anonymises the caller's account after checking their
+// password and, with two-factor authentication enabled, a TOTP or recovery
+// code. Wrong credentials count towards the same throttling and lockout as
+// failed logins, so a stolen access token cannot be used to guess the password.
+func DeleteOwnAccount(userID uuid.UUID, password, code, ip string) error {
+	var user models.User
+	if err := database.Database.Db.First(&user, "id = ?", userID).Error; err != nil {
+		return ErrUserNotFound
+	}
+	// The address is erased with the account, keep it for the counters
+	email := user.Email
+	if err := checkLoginAllowed(email, ip); err != nil {
+		return err
+	}
+
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return ErrUserNotFound
+		}
+		if match, _, err := passhash.Verify(user.Password, password); err != nil || !match {
+			return ErrInvalidCurrentPassword
+		}
+		if user.TwoFactorEnabledAt != nil {
+			if err := verifySecondFactor(&user, code, tx); err != nil {
+				return err
+			}
+		}
+		return anonymizeUser(&user, tx)
+	})
+	if err == ErrInvalidCurrentPassword || err == ErrInvalidTwoFactorCode {
+		recordLoginFailure(email, ip)
+	}
+	if err != nil {
+		return err
+	}
+	clearLoginFailures(email)
+	return nil
+}
+
+// DeleteUserAccount anonymises another user's account on behalf of staff.
+func DeleteUserAccount(userID, actorID uuid.UUID, actorRole string) (*models.User, error) {
+	return manageUser(userID, actorID, actorRole, anonymizeUser)
+}
+
+// anonymizeUser erases the personal data of the user and signs them out
+// everywhere. Orders, order items and payments are kept for accounting and
+// stay linked to the anonymised account.
+func anonymizeUser(user *models.User, tx *gorm.DB) error {
+	if user.AnonymizedAt != nil {
+		return ErrAccountDeleted
+	}
+	if user.Role == models.Admin {
+		var admins int64
+		if err := tx.Model(&models.User{}).Where("role = ? AND anonymized_at IS NULL", models.Admin).Count(&admins).Error; err != nil {
+			return err
+		}
+		if admins <= 1 {
+			return ErrLastAdmin
+		}
+	}
+
+	if err := RevokeAllUserTokens(user.ID, tx); err != nil {
+		return err
+	}
+
+	email := normalizeEmail(user.Email)
+	cartIDs := tx.Model(&models.Cart{}).Select("id").Where("user_refer = ?", user.ID)
+	deletions := []struct {
+		model interface{}
+		query *gorm.DB
+	}{
+		{&models.CartItem{}, tx.Where("cart_refer IN (?)", cartIDs)},
+		{&models.Cart{}, tx.Where("user_refer = ?", user.ID)},
+		{&models.Session{}, tx.Where("user_refer = ?", user.ID)},
+		{&models.RefreshToken{}, tx.Where("user_refer = ?", user.ID)},
+		{&models.PasswordResetToken{}, tx.Where("user_refer = ?", user.ID)},
+		{&models.RecoveryCode{}, tx.Where("user_refer = ?", user.ID)},
+		{&models.UserIdentity{}, tx.Where("user_refer = ?", user.ID)},
+		{&models.LoginAttempt{}, tx.Where("attempt_key = ?", accountAttemptKey(email))},
+	}
+	for _, d := range deletions {
+		if err := d.query.Delete(d.model).Error; err != nil {
+			return err
+		}
+	}
+
+	// Security events stay for auditing without the address and IP
+	if err := tx.Model(&models.SecurityEvent{}).
+		Where("user_refer = ? OR email = ?", user.ID, email).
+		Updates(map[string]interface{}{"email": "", "ip": ""}).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"name":                    "Deleted user",
+		"email":                   "deleted-" + user.ID.String() + "@invalid",
+		"password":                "",
+		"role":                    models.Customer,
+		"verified_at":             nil,
+		"totp_secret":             "",
+		"totp_last_used_step":     0,
+		"two_factor_enabled_at":   nil,
+		"suspension_reason":       "",
+		"password_reset_required": false,
+		"anonymized_at":           now,
+	}
+	if err := tx.Model(user).Updates(updates).Error; err != nil {
+		return err
+	}
+	return tx.First(user, "id = ?", user.ID).Error
+}
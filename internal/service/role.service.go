@@ -0,0 +1,57 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrCannotChangeOwnRole = errors.New("you cannot change your own role")
+	ErrLastAdmin           = errors.New("cannot remove the role of the last admin")
+)
+
+// AssignRole changes the role of a user. Tokens issued for the previous role
+// are revoked so the new permissions apply immediately.
+func AssignRole(userID uuid.UUID, role string, actorID uuid.UUID) (*models.User, error) {
+	if !models.IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if userID == actorID {
+		return nil, ErrCannotChangeOwnRole
+	}
+
+	var user models.User
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return ErrUserNotFound
+		}
+		if user.Role == role {
+			return nil
+		}
+
+		if user.Role == models.Admin {
+			var admins int64
+			if err := tx.Model(&models.User{}).Where("role = ?", models.Admin).Count(&admins).Error; err != nil {
+				return err
+			}
+			if admins <= 1 {
+				return ErrLastAdmin
+			}
+		}
+
+		user.Role = role
+		if err := tx.Model(&user).Update("role", role).Error; err != nil {
+			return err
+		}
+		return RevokeAllUserTokens(user.ID, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/tracing"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sessionActivityInterval limits how often last activity is written to the database
+const sessionActivityInterval = time.Minute
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionService lists and signs out the sessions of the signed in user
+type SessionService struct {
+	store repository.Store
+}
+
+func NewSessionService(store repository.Store) *SessionService {
+	return &SessionService{store: store}
+}
+
+// GetSessions lists the active sessions of the user, most recently used first.
+func (s *SessionService) GetSessions(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	ctx, span := tracing.Start(ctx, "SessionService.GetSessions")
+	defer span.End()
+
+	return s.store.WithContext(ctx).Sessions().ListActive(userID)
+}
+
+// RevokeSession signs the user out of one session. Its access and refresh
+// tokens stop working immediately.
+func (s *SessionService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "SessionService.RevokeSession")
+	defer span.End()
+
+	err := s.store.WithContext(ctx).Transaction(func(store repository.Store) error {
+		return store.Sessions().Revoke(userID, sessionID)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrSessionNotFound
+	}
+	return err
+}
+
+// SignOutEverywhere revokes every session of the user, including the current one.
+func (s *SessionService) SignOutEverywhere(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "SessionService.SignOutEverywhere")
+	defer span.End()
+
+	return s.store.WithContext(ctx).Transaction(func(store repository.Store) error {
+		return store.Users().RevokeTokens(userID)
+	})
+}
+
+func createSession(userID uuid.UUID, client ClientInfo, sessions repository.SessionRepository) (*models.Session, error) {
+	now := time.Now()
+	session := models.Session{
+		UserRefer:    userID,
+		UserAgent:    truncate(client.UserAgent, 255),
+		IP:           client.IP,
+		LastActiveAt: now,
+		ExpiresAt:    now.Add(utils.RefreshTokenTTL),
+	}
+	if err := sessions.Create(&session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// continueSession extends the session of a refresh token being rotated.
+// Tokens issued before sessions were tracked get a new session.
+func continueSession(refreshToken models.RefreshToken, client ClientInfo, tx *gorm.DB) (uuid.UUID, error) {
+	if refreshToken.SessionRefer == nil {
+		session, err := createSession(refreshToken.UserRefer, client, repository.NewStore(tx).Sessions())
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return session.ID, nil
+	}
+
+	now := time.Now()
+	result := tx.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", *refreshToken.SessionRefer).
+		Updates(map[string]interface{}{
+			"last_active_at": now,
+			"expires_at":     now.Add(utils.RefreshTokenTTL),
+			"ip":             client.IP,
+			"user_agent":     truncate(client.UserAgent, 255),
+		})
+	if result.Error != nil {
+		return uuid.Nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return uuid.Nil, ErrInvalidRefreshToken
+	}
+	return *refreshToken.SessionRefer, nil
+}
+
+// touchSession checks that the session of an access token is still active and
+// records its activity.
+func touchSession(sessionID string, userID uuid.UUID) error {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return ErrTokenRevoked
+	}
+
+	var session models.Session
+	if err := database.Database.Db.First(&session, "id = ? AND user_refer = ?", id, userID).Error; err != nil {
+		return ErrTokenRevoked
+	}
+	now := time.Now()
+	if session.RevokedAt != nil || now.After(session.ExpiresAt) {
+		return ErrTokenRevoked
+	}
+
+	if now.Sub(session.LastActiveAt) > sessionActivityInterval {
+		return database.Database.Db.Model(&session).UpdateColumn("last_active_at", now).Error
+	}
+	return nil
+}
+
+// truncate shortens s to at most max bytes without splitting a UTF-8 character
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrTokenRevoked        = errors.New("token has been revoked")
+)
+
+// TokenPair is the pair of credentials handed to a client after authenticating
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
+
+// ClientInfo describes the device a session was started from
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// issueTokenPair starts a new session for the user and returns its access token
+// and persisted refresh token
+func issueTokenPair(user *models.User, mfa bool, client ClientInfo, sessions repository.SessionRepository) (*TokenPair, error) {
+	session, err := createSession(user.ID, client, sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := generateAccessToken(user, mfa, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, _, err := createRefreshToken(user.ID, session.ID, mfa, sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(utils.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// RotateRefreshToken exchanges a valid refresh token for a new token pair. The
+// presented token is revoked; presenting a token that was already exchanged is
+// treated as token theft and revokes every outstanding token of its owner.
+func RotateRefreshToken(refreshToken string, client ClientInfo) (*TokenPair, error) {
+	var pair *TokenPair
+	var reused *uuid.UUID
+
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		var stored models.RefreshToken
+		if err := tx.Where("token_hash = ?", utils.HashToken(refreshToken)).First(&stored).Error; err != nil {
+			return ErrInvalidRefreshToken
+		}
+		if stored.RevokedAt != nil {
+			// Only a rotated token presented again may have been stolen, tokens
+			// revoked by logout are just refused
+			if stored.ReplacedBy != nil {
+				reused = &stored.UserRefer
+			}
+			return ErrInvalidRefreshToken
+		}
+		if time.Now().After(stored.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		var user models.User
+		if err := tx.First(&user, "id = ?", stored.UserRefer).Error; err != nil {
+			return ErrInvalidRefreshToken
+		}
+		if user.SuspendedAt != nil {
+			return ErrAccountSuspended
+		}
+
+		sessionID, err := continueSession(stored, client, tx)
+		if err != nil {
+			return err
+		}
+
+		accessToken, err := generateAccessToken(&user, stored.MFA, sessionID)
+		if err != nil {
+			return err
+		}
+		newRefreshToken, newID, err := createRefreshToken(user.ID, sessionID, stored.MFA, repository.NewStore(tx).Sessions())
+		if err != nil {
+			return err
+		}
+
+		// Guard against two concurrent refreshes with the same token
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", stored.ID).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "replaced_by": newID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Revoked meanwhile, by a concurrent refresh or by logout
+			if err := tx.First(&stored, "id = ?", stored.ID).Error; err == nil && stored.ReplacedBy != nil {
+				reused = &stored.UserRefer
+			}
+			return ErrInvalidRefreshToken
+		}
+
+		pair = &TokenPair{
+			AccessToken:  accessToken,
+			RefreshToken: newRefreshToken,
+			ExpiresIn:    int64(utils.AccessTokenTTL.Seconds()),
+		}
+		return nil
+	})
+
+	if reused != nil {
+		if err := RevokeAllUserTokens(*reused, database.Database.Db); err != nil {
+			return nil, err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// revokeAccessToken adds the access token's ID to the revocation list until it expires.
+func revokeAccessToken(claims *utils.Claims, sessions repository.SessionRepository) error {
+	tokenID, err := uuid.Parse(claims.Id)
+	if err != nil {
+		return utils.ErrInvalidToken
+	}
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return utils.ErrInvalidToken
+	}
+
+	return sessions.RevokeAccessToken(&models.RevokedToken{
+		TokenID:   tokenID,
+		UserRefer: userID,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	})
+}
+
+// RevokeAllUserTokens invalidates every session, access and refresh token of the user.
+func RevokeAllUserTokens(userID uuid.UUID, tx *gorm.DB) error {
+	return repository.NewStore(tx).Users().RevokeTokens(userID)
+}
+
+// ValidateAccessToken checks that a signature-valid access token has not been
+// revoked, either on its own or through its session.
+func ValidateAccessToken(claims *utils.Claims) error {
+	db := database.Database.Db
+
+	var user models.User
+	if err := db.Select("id", "token_version", "suspended_at").First(&user, "id = ?", claims.UserID).Error; err != nil {
+		return ErrTokenRevoked
+	}
+	if user.TokenVersion != claims.TokenVersion {
+		return ErrTokenRevoked
+	}
+	if user.SuspendedAt != nil {
+		return ErrAccountSuspended
+	}
+
+	var count int64
+	if err := db.Model(&models.RevokedToken{}).Where("token_id = ?", claims.Id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrTokenRevoked
+	}
+
+	// Impersonation tokens have no session, they last as long as the actor may impersonate
+	if claims.Actor != nil {
+		return validateImpersonator(claims.Actor.UserID)
+	}
+	return touchSession(claims.SessionID, user.ID)
+}
+
+func generateAccessToken(user *models.User, mfa bool, sessionID uuid.UUID) (string, error) {
+	return utils.GenerateJWT(&utils.Claims{
+		UserID:       user.ID.String(),
+		Role:         user.Role,
+		TokenVersion: user.TokenVersion,
+		MFA:          mfa,
+		SessionID:    sessionID.String(),
+	})
+}
+
+func createRefreshToken(userID, sessionID uuid.UUID, mfa bool, sessions repository.SessionRepository) (string, uuid.UUID, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+
+	refreshToken := models.RefreshToken{
+		UserRefer:    userID,
+		SessionRefer: &sessionID,
+		TokenHash:    utils.HashToken(token),
+		ExpiresAt:    time.Now().Add(utils.RefreshTokenTTL),
+		MFA:          mfa,
+	}
+	if err := sessions.CreateRefreshToken(&refreshToken); err != nil {
+		return "", uuid.Nil, err
+	}
+	return token, refreshToken.ID, nil
+}
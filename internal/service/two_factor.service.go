@@ -0,0 +1,304 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/totp"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	loginChallengePurpose = "login_challenge"
+	recoveryCodeCount     = 10
+	recoveryCodeAlphabet  = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotInitiated   = errors.New("two-factor setup has not been started")
+	ErrTwoFactorMandatory      = errors.New("two-factor authentication is mandatory for this account")
+	ErrInvalidTwoFactorCode    = errors.New("invalid authentication code")
+	ErrInvalidLoginChallenge   = errors.New("invalid or expired login challenge")
+)
+
+var (
+	RequireAdminTwoFactor = config.Get().Auth.RequireAdminTwoFactor
+	LoginChallengeTTL     = config.Get().Auth.LoginChallengeTTL
+)
+
+// TwoFactorSetup holds what an authenticator app needs to enroll the user
+type TwoFactorSetup struct {
+	Secret string
+	URI    string
+}
+
+// IsTwoFactorRequired reports whether the policy forces the role to use a second factor.
+func IsTwoFactorRequired(role string) bool {
+	return RequireAdminTwoFactor && role == models.Admin
+}
+
+// SetupTwoFactor generates a new TOTP secret for the user. It only becomes
+// active once confirmed with a code from the authenticator app.
+func SetupTwoFactor(userID uuid.UUID) (*TwoFactorSetup, error) {
+	var user models.User
+	if err := database.Database.Db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.TwoFactorEnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := database.Database.Db.Model(&user).Update("totp_secret", secret).Error; err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorSetup{Secret: secret, URI: totp.URI(totpIssuer(), user.Email, secret)}, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication and returns a fresh set of recovery codes.
+func ConfirmTwoFactor(userID uuid.UUID, code string) ([]string, error) {
+	var codes []string
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return ErrUserNotFound
+		}
+		if user.TwoFactorEnabledAt != nil {
+			return ErrTwoFactorAlreadyEnabled
+		}
+		if user.TOTPSecret == "" {
+			return ErrTwoFactorNotInitiated
+		}
+		if err := verifyTOTP(&user, code, tx); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&user).Update("two_factor_enabled_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(user.ID, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off after checking a TOTP or recovery code.
+func DisableTwoFactor(userID uuid.UUID, code string) error {
+	return database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return ErrUserNotFound
+		}
+		if user.TwoFactorEnabledAt == nil {
+			return ErrTwoFactorNotEnabled
+		}
+		if IsTwoFactorRequired(user.Role) {
+			return ErrTwoFactorMandatory
+		}
+		if err := verifySecondFactor(&user, code, tx); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_secret":           "",
+			"totp_last_used_step":   0,
+			"two_factor_enabled_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_refer = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the user after checking a TOTP code.
+func RegenerateRecoveryCodes(userID uuid.UUID, code string) ([]string, error) {
+	var codes []string
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return ErrUserNotFound
+		}
+		if user.TwoFactorEnabledAt == nil {
+			return ErrTwoFactorNotEnabled
+		}
+		if err := verifyTOTP(&user, code, tx); err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(user.ID, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// CreateLoginChallenge returns a short-lived token proving the password step of a two-step login.
+func CreateLoginChallenge(user *models.User) (string, error) {
+	return utils.GenerateActionToken(loginChallengePurpose, user.ID, user.Email, LoginChallengeTTL)
+}
+
+// CompleteLoginChallenge verifies the second factor of a two-step login and
+// returns the authenticated user. code may be a TOTP or a recovery code.
+// Wrong codes count towards the same lockout as wrong passwords. A challenge
+// completes one login only.
+func CompleteLoginChallenge(challengeToken, code, ip string) (*models.User, error) {
+	claims, err := utils.ParseActionToken(challengeToken, loginChallengePurpose)
+	if err != nil {
+		return nil, ErrInvalidLoginChallenge
+	}
+	if err := checkLoginAllowed(claims.Email, ip); err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	err = database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, "id = ?", claims.Subject).Error; err != nil {
+			return ErrInvalidLoginChallenge
+		}
+		if user.TwoFactorEnabledAt == nil {
+			return ErrInvalidLoginChallenge
+		}
+		if user.SuspendedAt != nil {
+			return ErrAccountSuspended
+		}
+		if err := consumeLoginChallenge(claims, user.ID, tx); err != nil {
+			return err
+		}
+		return verifySecondFactor(&user, code, tx)
+	})
+	if err != nil {
+		if err == ErrInvalidTwoFactorCode {
+			recordLoginFailure(claims.Email, ip)
+		}
+		return nil, err
+	}
+
+	clearLoginFailures(claims.Email)
+	return &user, nil
+}
+
+// consumeLoginChallenge records the challenge ID in the revocation list until
+// the challenge expires, failing when it is there already. A wrong code rolls
+// the transaction back, so the challenge can be tried again.
+func consumeLoginChallenge(claims *utils.ActionClaims, userID uuid.UUID, tx *gorm.DB) error {
+	challengeID, err := uuid.Parse(claims.Id)
+	if err != nil {
+		return ErrInvalidLoginChallenge
+	}
+	// The primary key keeps concurrent logins with the same challenge to one
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.RevokedToken{
+		TokenID:   challengeID,
+		UserRefer: userID,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidLoginChallenge
+	}
+	return nil
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code.
+func verifySecondFactor(user *models.User, code string, tx *gorm.DB) error {
+	if err := verifyTOTP(user, code, tx); err == nil {
+		return nil
+	}
+
+	normalized := strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+	result := tx.Model(&models.RecoveryCode{}).
+		Where("user_refer = ? AND code_hash = ? AND used_at IS NULL", user.ID, utils.HashToken(normalized)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// verifyTOTP checks a TOTP code and rejects replays of an already used code.
+func verifyTOTP(user *models.User, code string, tx *gorm.DB) error {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok || step <= user.TOTPLastUsedStep {
+		return ErrInvalidTwoFactorCode
+	}
+
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND totp_last_used_step < ?", user.ID, step).
+		Update("totp_last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	user.TOTPLastUsedStep = step
+	return nil
+}
+
+func replaceRecoveryCodes(userID uuid.UUID, tx *gorm.DB) ([]string, error) {
+	if err := tx.Where("user_refer = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		recoveryCode := models.RecoveryCode{UserRefer: userID, CodeHash: utils.HashToken(strings.ReplaceAll(code, "-", ""))}
+		if err := tx.Create(&recoveryCode).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns a code formatted as xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	code := make([]byte, 0, 11)
+	for i := 0; i < 10; i++ {
+		if i == 5 {
+			code = append(code, '-')
+		}
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		code = append(code, recoveryCodeAlphabet[n.Int64()])
+	}
+	return string(code), nil
+}
+
+func totpIssuer() string {
+	return config.Get().Auth.TOTPIssuer
+}
@@ -1,68 +1,83 @@
 package service
 
 import (
+	"context"
 	"errors"
 
-	"github.com/arsyaputraa/go-synapsis-challenge/database"
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/passhash"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/tracing"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
 	ErrInvalidCurrentPassword = errors.New("incorrect current password")
 )
 
-// GetUserByID retrieves a user by their ID.
-func GetUserByID(userID uuid.UUID) (*models.User, error) {
-	var user models.User
-	if err := database.Database.Db.First(&user, "id = ?", userID).Error; err != nil {
+// UserService manages the profile of the signed in user
+type UserService struct {
+	store repository.Store
+}
+
+func NewUserService(store repository.Store) *UserService {
+	return &UserService{store: store}
+}
+
+// GetUser retrieves a user by their ID.
+func (s *UserService) GetUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUser")
+	defer span.End()
+
+	user, err := s.store.WithContext(ctx).Users().FindByID(userID)
+	if errors.Is(err, repository.ErrNotFound) {
 		return nil, ErrUserNotFound
 	}
-	return &user, nil
+	return user, err
 }
 
 // UpdateUserDetails updates user details like name.
-func UpdateUserDetails(userID uuid.UUID, name string) (*models.User, error) {
-	var user models.User
-	if err := database.Database.Db.First(&user, "id = ?", userID).Error; err != nil {
+func (s *UserService) UpdateUserDetails(ctx context.Context, userID uuid.UUID, name string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUserDetails")
+	defer span.End()
+
+	err := s.store.WithContext(ctx).Users().UpdateName(userID, name)
+	if errors.Is(err, repository.ErrNotFound) {
 		return nil, ErrUserNotFound
 	}
-
-	user.Name = name
-
-	if err := database.Database.Db.Save(&user).Error; err != nil {
+	if err != nil {
 		return nil, err
 	}
 
-	return &user, nil
+	return s.GetUser(ctx, userID)
 }
 
 // UpdateUserPassword updates the password for the user.
-func UpdateUserPassword(userID uuid.UUID, currentPassword, newPassword string) error {
-	var user models.User
+func (s *UserService) UpdateUserPassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUserPassword")
+	defer span.End()
 
-	// Find the user by ID
-	if err := database.Database.Db.First(&user, "id = ?", userID).Error; err != nil {
-		return ErrUserNotFound
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
 	}
 
 	// Verify the current password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+	if match, _, err := passhash.Verify(user.Password, currentPassword); err != nil || !match {
 		return ErrInvalidCurrentPassword
 	}
 
 	// Hash the new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	hashedPassword, err := passhash.Hash(newPassword)
 	if err != nil {
 		return err
 	}
 
-	// Update the password
-	user.Password = string(hashedPassword)
-	if err := database.Database.Db.Save(&user).Error; err != nil {
-		return err
-	}
-
-	return nil
+	// Update the password and sign the user out of every session
+	return s.store.WithContext(ctx).Transaction(func(store repository.Store) error {
+		if err := store.Users().UpdatePassword(user.ID, hashedPassword); err != nil {
+			return err
+		}
+		return store.Users().RevokeTokens(user.ID)
+	})
 }
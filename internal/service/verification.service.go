@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/mail"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/google/uuid"
+)
+
+const emailVerificationPurpose = "email_verification"
+
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrEmailNotVerified         = errors.New("email address is not verified")
+)
+
+var EmailVerificationTTL = config.Get().Auth.EmailVerificationTTL
+
+// SendVerificationEmail emails the user a signed link proving ownership of their address.
+func SendVerificationEmail(user *models.User) error {
+	token, err := utils.GenerateActionToken(emailVerificationPurpose, user.ID, user.Email, EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/auth/verify?token=%s", appBaseURL(), url.QueryEscape(token))
+	return mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.Name, link, EmailVerificationTTL),
+	})
+}
+
+// VerifyEmail marks the user owning the token as verified. A token can only be used once.
+func VerifyEmail(token string) (*models.User, error) {
+	claims, err := utils.ParseActionToken(token, emailVerificationPurpose)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	var user models.User
+	if err := database.Database.Db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+	if user.VerifiedAt != nil || !strings.EqualFold(user.Email, claims.Email) {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	now := time.Now()
+	user.VerifiedAt = &now
+	if err := database.Database.Db.Model(&user).Update("verified_at", now).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ResendVerificationEmail sends a new verification link. Unknown or already
+// verified addresses are ignored, failed deliveries only logged and the work
+// runs in the background, so the endpoint cannot be used to probe accounts.
+func ResendVerificationEmail(email string) {
+	runInBackground("verification email", func() {
+		var user models.User
+		if err := database.Database.Db.Where("email = ?", email).First(&user).Error; err != nil {
+			return
+		}
+		if user.VerifiedAt != nil {
+			return
+		}
+		if err := SendVerificationEmail(&user); err != nil {
+			slog.Error("failed to send verification email", "user_id", user.ID, "error", err)
+		}
+	})
+}
+
+func appBaseURL() string {
+	return strings.TrimRight(config.Get().Server.BaseURL, "/")
+}
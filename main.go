@@ -1,18 +1,41 @@
 package main
 
 import (
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
 
 	"github.com/arsyaputraa/go-synapsis-challenge/database"
 	_ "github.com/arsyaputraa/go-synapsis-challenge/docs"
-	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/router"
 	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/gofiber/swagger"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/logging"
 )
 
+// command is a subcommand of the binary
+type command struct {
+	name        string
+	usage       string
+	description string
+	// database is set for commands that connect to the database
+	database bool
+	run      func(args []string) error
+}
+
+var commands = []command{
+	{name: "serve", usage: "serve", description: "run the API server (default)", database: true, run: runServe},
+	{name: "migrate", usage: "migrate up [steps] | down [steps] | status", description: "apply, revert or list schema migrations", database: true, run: runMigrate},
+	{name: "seed", usage: "seed --file <path>", description: "create or update the records of a JSON or YAML fixture file", database: true, run: runSeed},
+	{name: "create-admin", usage: "create-admin --email <email> [--name <name>] [--password <password>]", description: "create an admin account, the password is read from stdin when omitted", database: true, run: runCreateAdmin},
+	{name: "reset-password", usage: "reset-password --email <email> [--password <password>]", description: "set the password of an account and sign it out everywhere", database: true, run: runResetPassword},
+	{name: "routes", usage: "routes", description: "list the API routes", run: runRoutes},
+}
+
+// errUsage makes a command print its usage
+var errUsage = errors.New("invalid arguments")
+
 // @title Online Store API
 // @version 1.0
 // @description This is an online store API using Golang, Fiber, and GORM.
@@ -24,18 +47,103 @@ import (
 // @in header
 // @name Authorization
 // @description "Bearer <token>"
-func init() {
-	config.LoadEnv()
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+func main() {
+	args := os.Args[1:]
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage()
+		return
+	}
+	logging.Setup(config.Get().Log)
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		err := flag.ErrHelp
+		if !wantsHelp(args[1:]) {
+			if cmd.database {
+				connect()
+			}
+			err = cmd.run(args[1:])
+			if cmd.database {
+				closeDatabase()
+			}
+		}
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stdout, "usage: %s %s\n%s\n", os.Args[0], cmd.usage, cmd.description)
+			return
+		}
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(os.Stderr, "usage: %s %s\n", os.Args[0], cmd.usage)
+			os.Exit(2)
+		}
+		if err != nil {
+			slog.Error(cmd.name+" failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	printUsage()
+	os.Exit(2)
+}
+
+// connect validates the configuration and connects to the database
+func connect() {
+	if err := config.Get().Validate(); err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(2)
+	}
 	database.Connect()
 }
 
-func main() {
-	database.InitializeAdminUser()
-
-	app := fiber.New()
-	app.Use(logger.New())
-	app.Use(cors.New())
-	app.Get("/swagger/*", swagger.HandlerDefault)
-	router.SetupRoutes(app)
-	log.Fatal(app.Listen(":8080"))
+// closeDatabase closes the connection pool once the command is done with it
+func closeDatabase() {
+	if err := database.Close(); err != nil {
+		slog.Warn("could not close the database connections", "error", err)
+	}
+}
+
+// wantsHelp reports whether the arguments ask for help, which is answered
+// before connecting to the database
+func wantsHelp(args []string) bool {
+	for _, arg := range args {
+		if arg == "-h" || arg == "-help" || arg == "--help" {
+			return true
+		}
+	}
+	return false
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [arguments]\n\ncommands:\n", os.Args[0])
+	w := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", cmd.usage, cmd.description)
+	}
+	w.Flush()
+}
+
+// parseFlags parses the arguments of a command. Usage errors are reported by
+// main, which prints the usage of the command.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	flags.Usage = func() {}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if flags.NArg() > 0 {
+		return errUsage
+	}
+	return nil
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+)
+
+// runMigrate runs the migrate subcommand. up applies every pending migration
+// unless a number of steps is given, down reverts one unless told otherwise.
+func runMigrate(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errUsage
+	}
+	steps := 0
+	if len(args) == 2 {
+		parsed, err := strconv.Atoi(args[1])
+		if err != nil || parsed < 1 {
+			return errUsage
+		}
+		steps = parsed
+	}
+
+	db := database.Database.Db
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(db, steps)
+		for _, migration := range applied {
+			fmt.Printf("applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		if steps == 0 {
+			steps = 1
+		}
+		reverted, err := database.MigrateDown(db, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("no applied migrations")
+		}
+		return err
+	case "status":
+		if len(args) > 1 {
+			return errUsage
+		}
+		statuses, err := database.GetMigrationStatus(db)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			if status.Missing {
+				appliedAt += " (file missing)"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return errUsage
+	}
+}
@@ -1,28 +1,195 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
-// Config func to get env value from key ---
+var (
+	settings *Settings
+	once     sync.Once
+)
+
+// Get returns the settings, loading them on first use. Problems found while
+// loading are reported by Validate, which main calls at startup.
+func Get() *Settings {
+	once.Do(func() {
+		settings = load()
+	})
+	return settings
+}
+
+// Config returns the environment variable key, with .env loaded. Prefer Get,
+// this is for dynamic keys such as the OIDC_<NAME>_* provider settings.
 func Config(key string) string {
-	// load .env file
-	err := godotenv.Load(".env")
+	Get()
+	return os.Getenv(key)
+}
+
+// ConfigBool reads a boolean environment variable, falling back to def
+func ConfigBool(key string, def bool) bool {
+	value := Config(key)
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.ParseBool(value)
 	if err != nil {
-		fmt.Print("Error loading .env file")
+		return def
 	}
-	return os.Getenv(key)
+	return parsed
+}
+
+// load applies defaults, then the YAML file, then the environment
+func load() *Settings {
+	s := &Settings{}
+
+	// A missing .env is fine, the environment may be set by other means
+	if err := godotenv.Load(".env"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		s.errs = append(s.errs, fmt.Errorf(".env: %w", err))
+	}
+
+	walk(reflect.ValueOf(s).Elem(), func(field reflect.Value, tag reflect.StructTag) {
+		if def, ok := tag.Lookup("default"); ok {
+			if err := setField(field, def); err != nil {
+				s.errs = append(s.errs, fmt.Errorf("default of %s: %w", tag.Get("env"), err))
+			}
+		}
+	})
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			err = yaml.Unmarshal(data, s)
+		}
+		if err != nil {
+			s.errs = append(s.errs, fmt.Errorf("CONFIG_FILE %s: %w", path, err))
+		}
+	}
+
+	walk(reflect.ValueOf(s).Elem(), func(field reflect.Value, tag reflect.StructTag) {
+		key := tag.Get("env")
+		if value, ok := os.LookupEnv(key); ok && key != "" && value != "" {
+			if err := setField(field, value); err != nil {
+				s.errs = append(s.errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+	})
+	return s
 }
 
-func LoadEnv() {
+// Validate reports the problems met while loading and the settings that are
+// missing or out of range, named by their environment variable.
+func (s *Settings) Validate() error {
+	errs := append([]error{}, s.errs...)
+
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		if key := field.Tag.Get("env"); key != "" {
+			return key
+		}
+		return field.Name
+	})
+	if err := validate.Struct(s); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return err
+		}
+		for _, fieldErr := range validationErrors {
+			if fieldErr.Param() != "" {
+				errs = append(errs, fmt.Errorf("%s: must satisfy %s=%s", fieldErr.Field(), fieldErr.Tag(), fieldErr.Param()))
+			} else {
+				errs = append(errs, fmt.Errorf("%s: must satisfy %s", fieldErr.Field(), fieldErr.Tag()))
+			}
+		}
+	}
+	if s.Server.ProxyHeader != "" && len(s.Server.TrustedProxies) == 0 {
+		errs = append(errs, errors.New("TRUSTED_PROXIES: must list the proxies setting PROXY_HEADER"))
+	}
+	return errors.Join(errs...)
+}
 
-	err := godotenv.Load()
+// String renders the settings as YAML with secrets redacted
+func (s *Settings) String() string {
+	out, err := yaml.Marshal(s)
 	if err != nil {
-		log.Fatalf("Error loading .env file")
+		return err.Error()
 	}
+	return string(out)
+}
+
+// walk calls fn for every settings field, descending into nested sections
+func walk(v reflect.Value, fn func(field reflect.Value, tag reflect.StructTag)) {
+	for i := 0; i < v.NumField(); i++ {
+		field, structField := v.Field(i), v.Type().Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		if field.Kind() == reflect.Struct && field.Type() != reflect.TypeOf(time.Duration(0)) {
+			walk(field, fn)
+			continue
+		}
+		fn(field, structField.Tag)
+	}
+}
 
+// setField parses value into field. Lists are comma separated.
+func setField(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Uint8, reflect.Uint32:
+		parsed, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
 }
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loadWith loads the settings from the YAML document and the environment
+// variables only, ignoring the environment of the process
+func loadWith(t *testing.T, yamlDoc string, env map[string]string) *Settings {
+	t.Helper()
+	// Empty variables are ignored by load
+	walk(reflect.ValueOf(&Settings{}).Elem(), func(_ reflect.Value, tag reflect.StructTag) {
+		if key := tag.Get("env"); key != "" {
+			t.Setenv(key, "")
+		}
+	})
+	t.Setenv("CONFIG_FILE", "")
+	if yamlDoc != "" {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(yamlDoc), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("CONFIG_FILE", path)
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	return load()
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	const yamlDoc = `
+server:
+  address: ":9090"
+  cors_origins: ["https://shop.example"]
+login:
+  attempt_window: 5m
+`
+	tests := []struct {
+		name        string
+		yaml        string
+		env         map[string]string
+		wantAddress string
+		wantWindow  time.Duration
+		wantOrigins []string
+	}{
+		{
+			name:        "defaults",
+			wantAddress: ":8080",
+			wantWindow:  15 * time.Minute,
+			wantOrigins: []string{"*"},
+		},
+		{
+			name:        "yaml over defaults",
+			yaml:        yamlDoc,
+			wantAddress: ":9090",
+			wantWindow:  5 * time.Minute,
+			wantOrigins: []string{"https://shop.example"},
+		},
+		{
+			name: "env over yaml",
+			yaml: yamlDoc,
+			env: map[string]string{
+				"SERVER_ADDRESS":       ":7070",
+				"CORS_ALLOWED_ORIGINS": "https://a.example, https://b.example,",
+			},
+			wantAddress: ":7070",
+			wantWindow:  5 * time.Minute,
+			wantOrigins: []string{"https://a.example", "https://b.example"},
+		},
+		{
+			name:        "empty env keeps yaml",
+			yaml:        yamlDoc,
+			env:         map[string]string{"SERVER_ADDRESS": "", "LOGIN_ATTEMPT_WINDOW": ""},
+			wantAddress: ":9090",
+			wantWindow:  5 * time.Minute,
+			wantOrigins: []string{"https://shop.example"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := loadWith(t, tt.yaml, tt.env)
+			if len(s.errs) > 0 {
+				t.Fatalf("load errors: %v", s.errs)
+			}
+			if s.Server.Address != tt.wantAddress {
+				t.Errorf("address %q, want %q", s.Server.Address, tt.wantAddress)
+			}
+			if s.Login.AttemptWindow != tt.wantWindow {
+				t.Errorf("attempt window %s, want %s", s.Login.AttemptWindow, tt.wantWindow)
+			}
+			if !reflect.DeepEqual(s.Server.CORSOrigins, tt.wantOrigins) {
+				t.Errorf("CORS origins %q, want %q", s.Server.CORSOrigins, tt.wantOrigins)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := map[string]string{
+		"JWT_SECRET_KEY":     "jwt-secret",
+		"DB_HOST":            "localhost",
+		"DB_USER":            "store",
+		"DB_NAME":            "store",
+		"PASSWORD_RESET_URL": "https://shop.example/reset-password",
+	}
+	tests := []struct {
+		name string
+		yaml string
+		env  map[string]string
+		// want are the messages the error must contain, none when valid
+		want []string
+	}{
+		{name: "valid"},
+		{name: "sqlite needs no database server", env: map[string]string{"DB_DRIVER": "sqlite", "DB_HOST": ""}},
+		{name: "missing secret", env: map[string]string{"JWT_SECRET_KEY": ""}, want: []string{"JWT_SECRET_KEY: must satisfy required"}},
+		{name: "unknown environment", env: map[string]string{"APP_ENV": "staging"}, want: []string{"APP_ENV: must satisfy oneof=development production"}},
+		{name: "out of range", env: map[string]string{"TRACING_SAMPLE_RATIO": "2", "DB_PORT": "0"}, want: []string{
+			"TRACING_SAMPLE_RATIO: must satisfy lte=1",
+			"DB_PORT: must satisfy min=1",
+		}},
+		{name: "unparsable", env: map[string]string{"LOGIN_ATTEMPT_WINDOW": "soon", "SERVER_REQUEST_LOG": "maybe"}, want: []string{
+			`LOGIN_ATTEMPT_WINDOW: time: invalid duration "soon"`,
+			`SERVER_REQUEST_LOG: strconv.ParseBool: parsing "maybe"`,
+		}},
+		{name: "invalid url", env: map[string]string{"PASSWORD_RESET_URL": "reset-password"}, want: []string{"PASSWORD_RESET_URL: must satisfy url"}},
+		{name: "proxy header without trusted proxies", env: map[string]string{"PROXY_HEADER": "X-Forwarded-For"}, want: []string{
+			"TRUSTED_PROXIES: must list the proxies setting PROXY_HEADER",
+		}},
+		{name: "invalid trusted proxy", env: map[string]string{"PROXY_HEADER": "X-Forwarded-For", "TRUSTED_PROXIES": "10.0.0.0/8,proxy"}, want: []string{
+			"TRUSTED_PROXIES[1]: must satisfy ip|cidr",
+		}},
+		{name: "invalid yaml", yaml: "server: [", want: []string{"CONFIG_FILE"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{}
+			for key, value := range valid {
+				env[key] = value
+			}
+			for key, value := range tt.env {
+				env[key] = value
+			}
+
+			err := loadWith(t, tt.yaml, env).Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want %q", tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() = %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSecretsAreRedacted(t *testing.T) {
+	s := loadWith(t, "database:\n  password: yaml-db-password\n", map[string]string{
+		"JWT_SECRET_KEY": "env-jwt-secret",
+		"SMTP_PASSWORD":  "env-smtp-password",
+	})
+	if s.Database.Password.Value() != "yaml-db-password" || s.JWT.SecretKey.Value() != "env-jwt-secret" {
+		t.Fatalf("secrets not loaded: %q, %q", s.Database.Password.Value(), s.JWT.SecretKey.Value())
+	}
+
+	for format, out := range map[string]string{
+		"String()": s.String(),
+		"%+v":      fmt.Sprintf("%+v", *s),
+		"%#v":      fmt.Sprintf("%#v", *s),
+	} {
+		for _, secret := range []string{"yaml-db-password", "env-jwt-secret", "env-smtp-password"} {
+			if strings.Contains(out, secret) {
+				t.Errorf("%s shows the secret %s:\n%s", format, secret, out)
+			}
+		}
+		if !strings.Contains(out, redacted) {
+			t.Errorf("%s has no redacted secret:\n%s", format, out)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Settings is the application configuration. Each field is read, in order of
+// precedence, from its environment variable (tag env, also set from .env),
+// the YAML file named by CONFIG_FILE (tag yaml) and its default (tag default).
+type Settings struct {
+	// Environment is development or production. Production disables automatic
+	// schema changes on startup.
+	Environment string `yaml:"environment" env:"APP_ENV" default:"development" validate:"oneof=development production"`
+
+	Log      LogSettings      `yaml:"log"`
+	Tracing  TracingSettings  `yaml:"tracing"`
+	Server   ServerSettings   `yaml:"server"`
+	Database DatabaseSettings `yaml:"database"`
+	JWT      JWTSettings      `yaml:"jwt"`
+	Password PasswordSettings `yaml:"password"`
+	Auth     AuthSettings     `yaml:"auth"`
+	Login    LoginSettings    `yaml:"login"`
+	Mail     MailSettings     `yaml:"mail"`
+
+	// errs are the problems met while loading, reported by Validate
+	errs []error
+}
+
+// Production reports whether the application runs in production mode
+func (s *Settings) Production() bool {
+	return s.Environment == "production"
+}
+
+type LogSettings struct {
+	// Level is the lowest level of the messages logged
+	Level string `yaml:"level" env:"LOG_LEVEL" default:"info" validate:"oneof=debug info warn error"`
+	// Format is json, or text to read the logs in a terminal
+	Format string `yaml:"format" env:"LOG_FORMAT" default:"json" validate:"oneof=json text"`
+}
+
+type TracingSettings struct {
+	// Exporter sends the spans to an OTLP collector over HTTP, prints them to
+	// stdout, or is none to only propagate the trace context
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER" default:"none" validate:"oneof=none otlp stdout"`
+	// OTLPEndpoint is the collector URL, such as http://localhost:4318. When
+	// empty the OTEL_EXPORTER_OTLP_* variables apply.
+	OTLPEndpoint string `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT" validate:"omitempty,url"`
+	// SampleRatio is the share of traces started here that are recorded.
+	// Requests with a trace context follow the sampling decision of the caller.
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" default:"1" validate:"gte=0,lte=1"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME" default:"online-store" validate:"required"`
+}
+
+type ServerSettings struct {
+	Address      string        `yaml:"address" env:"SERVER_ADDRESS" default:":8080" validate:"required"`
+	ReadTimeout  time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" default:"10s" validate:"gte=0"`
+	WriteTimeout time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" default:"30s" validate:"gte=0"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" default:"60s" validate:"gte=0"`
+	// ShutdownTimeout is how long requests in flight may take to finish once
+	// SIGTERM or SIGINT is received
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" default:"30s" validate:"gt=0"`
+	// BaseURL is the public URL of the API, used in emailed links and OIDC redirects
+	BaseURL string `yaml:"base_url" env:"APP_BASE_URL" default:"http://localhost:8080" validate:"required,url"`
+	// ProxyHeader carries the client IP when running behind a reverse proxy
+	ProxyHeader string `yaml:"proxy_header" env:"PROXY_HEADER"`
+	// TrustedProxies are the IPs and CIDR ranges of the proxies ProxyHeader is
+	// read from. Other clients could set it to change IP on every request.
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES" validate:"dive,ip|cidr"`
+	CORSOrigins    []string `yaml:"cors_origins" env:"CORS_ALLOWED_ORIGINS" default:"*" validate:"required"`
+	RequestLog     bool     `yaml:"request_log" env:"SERVER_REQUEST_LOG" default:"true"`
+}
+
+type DatabaseSettings struct {
+	// Driver is postgres, or sqlite for local development and tests
+	Driver string `yaml:"driver" env:"DB_DRIVER" default:"postgres" validate:"oneof=postgres sqlite"`
+	// SQLitePath is the database file, :memory: for a database living as long as the process
+	SQLitePath string `yaml:"sqlite_path" env:"DB_SQLITE_PATH" default:"store.db" validate:"required_if=Driver sqlite"`
+	Host       string `yaml:"host" env:"DB_HOST" validate:"required_if=Driver postgres"`
+	Port       int    `yaml:"port" env:"DB_PORT" default:"5432" validate:"min=1,max=65535"`
+	User       string `yaml:"user" env:"DB_USER" validate:"required_if=Driver postgres"`
+	Password   Secret `yaml:"password" env:"DB_PASSWORD"`
+	Name       string `yaml:"name" env:"DB_NAME" validate:"required_if=Driver postgres"`
+	SSLMode    string `yaml:"sslmode" env:"DB_SSLMODE" default:"disable" validate:"oneof=disable allow prefer require verify-ca verify-full"`
+	TimeZone   string `yaml:"timezone" env:"DB_TIMEZONE" default:"Asia/Jakarta" validate:"required"`
+	// LogLevel of the SQL logger, info logs every statement at the debug level
+	LogLevel        string        `yaml:"log_level" env:"DB_LOG_LEVEL" default:"warn" validate:"oneof=silent error warn info"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"25" validate:"gte=0"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" default:"10" validate:"gte=0"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"30m" validate:"gte=0"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" default:"5m" validate:"gte=0"`
+	// SeedFile is seeded on startup outside production
+	SeedFile string `yaml:"seed_file" env:"SEED_FILE"`
+}
+
+type JWTSettings struct {
+	// SecretKey signs the tokens only this API reads, such as email links
+	SecretKey           Secret        `yaml:"secret_key" env:"JWT_SECRET_KEY" validate:"required"`
+	AccessTokenTTL      time.Duration `yaml:"access_token_ttl" env:"JWT_ACCESS_TOKEN_TTL" default:"15m" validate:"gt=0"`
+	RefreshTokenTTL     time.Duration `yaml:"refresh_token_ttl" env:"JWT_REFRESH_TOKEN_TTL" default:"168h" validate:"gt=0"`
+	KeysDir             string        `yaml:"keys_dir" env:"JWT_KEYS_DIR" default:"keys" validate:"required"`
+	SigningAlgorithm    string        `yaml:"signing_alg" env:"JWT_SIGNING_ALG" default:"RS256" validate:"oneof=RS256 EdDSA"`
+	KeyRotationInterval time.Duration `yaml:"key_rotation_interval" env:"JWT_KEY_ROTATION_INTERVAL" default:"720h" validate:"gte=0"`
+	KeyRetention        time.Duration `yaml:"key_retention" env:"JWT_KEY_RETENTION" default:"24h" validate:"gtefield=AccessTokenTTL"`
+}
+
+type PasswordSettings struct {
+	Algorithm         string `yaml:"algorithm" env:"PASSWORD_HASH_ALGORITHM" default:"argon2id" validate:"oneof=bcrypt argon2id"`
+	BcryptCost        int    `yaml:"bcrypt_cost" env:"PASSWORD_BCRYPT_COST" default:"12" validate:"min=4,max=31"`
+	Argon2Memory      uint32 `yaml:"argon2_memory" env:"PASSWORD_ARGON2_MEMORY" default:"65536" validate:"min=8192"`
+	Argon2Iterations  uint32 `yaml:"argon2_iterations" env:"PASSWORD_ARGON2_ITERATIONS" default:"3" validate:"min=1"`
+	Argon2Parallelism uint8  `yaml:"argon2_parallelism" env:"PASSWORD_ARGON2_PARALLELISM" default:"4" validate:"min=1"`
+}
+
+type AuthSettings struct {
+	EmailVerificationTTL            time.Duration `yaml:"email_verification_ttl" env:"EMAIL_VERIFICATION_TTL" default:"24h" validate:"gt=0"`
+	RequireVerifiedEmailForCheckout bool          `yaml:"require_verified_email_for_checkout" env:"REQUIRE_VERIFIED_EMAIL_FOR_CHECKOUT" default:"true"`
+	PasswordResetTTL                time.Duration `yaml:"password_reset_ttl" env:"PASSWORD_RESET_TTL" default:"1h" validate:"gt=0"`
+	// PasswordResetURL is the page of the client where users choose a new
+	// password, the API serves none
+	PasswordResetURL      string        `yaml:"password_reset_url" env:"PASSWORD_RESET_URL" validate:"required,url"`
+	RequireAdminTwoFactor bool          `yaml:"require_admin_2fa" env:"REQUIRE_ADMIN_2FA" default:"true"`
+	LoginChallengeTTL     time.Duration `yaml:"login_challenge_ttl" env:"LOGIN_CHALLENGE_TTL" default:"5m" validate:"gt=0"`
+	TOTPIssuer            string        `yaml:"totp_issuer" env:"TOTP_ISSUER" default:"MyStore" validate:"required"`
+	ImpersonationTokenTTL time.Duration `yaml:"impersonation_token_ttl" env:"IMPERSONATION_TOKEN_TTL" default:"15m" validate:"gt=0"`
+	// OIDCProviders are configured with OIDC_<NAME>_* environment variables
+	OIDCProviders []string      `yaml:"oidc_providers" env:"OIDC_PROVIDERS"`
+	OIDCStateTTL  time.Duration `yaml:"oidc_state_ttl" env:"OIDC_STATE_TTL" default:"10m" validate:"gt=0"`
+}
+
+type LoginSettings struct {
+	AttemptStore           string        `yaml:"attempt_store" env:"LOGIN_ATTEMPT_STORE" default:"database" validate:"oneof=database memory"`
+	AttemptWindow          time.Duration `yaml:"attempt_window" env:"LOGIN_ATTEMPT_WINDOW" default:"15m" validate:"gt=0"`
+	DelayBase              time.Duration `yaml:"delay_base" env:"LOGIN_DELAY_BASE" default:"1s" validate:"gte=0"`
+	DelayMax               time.Duration `yaml:"delay_max" env:"LOGIN_DELAY_MAX" default:"30s" validate:"gte=0"`
+	AccountLockoutAttempts int           `yaml:"account_lockout_attempts" env:"ACCOUNT_LOCKOUT_ATTEMPTS" default:"5" validate:"min=1"`
+	IPLockoutAttempts      int           `yaml:"ip_lockout_attempts" env:"IP_LOCKOUT_ATTEMPTS" default:"20" validate:"min=1"`
+	LockoutDuration        time.Duration `yaml:"lockout_duration" env:"LOCKOUT_DURATION" default:"15m" validate:"gt=0"`
+}
+
+type MailSettings struct {
+	Driver       string `yaml:"driver" env:"MAIL_DRIVER" default:"file" validate:"oneof=file smtp memory"`
+	From         string `yaml:"from" env:"MAIL_FROM" default:"no-reply@mystore.com" validate:"required,email"`
+	FileDir      string `yaml:"file_dir" env:"MAIL_FILE_DIR" default:"tmp/mail"`
+	SMTPHost     string `yaml:"smtp_host" env:"SMTP_HOST" validate:"required_if=Driver smtp"`
+	SMTPPort     int    `yaml:"smtp_port" env:"SMTP_PORT" default:"587" validate:"min=1,max=65535"`
+	SMTPUsername string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword Secret `yaml:"smtp_password" env:"SMTP_PASSWORD"`
+}
+
+// Secret is a setting that is never printed, logged or marshalled
+type Secret string
+
+const redacted = "******"
+
+// Value returns the secret itself
+func (s Secret) Value() string { return string(s) }
+
+func (s Secret) String() string { return redactedOrEmpty(s) }
+
+func (s Secret) GoString() string { return redactedOrEmpty(s) }
+
+func (s Secret) MarshalYAML() (interface{}, error) { return redactedOrEmpty(s), nil }
+
+func (s Secret) MarshalJSON() ([]byte, error) { return []byte(`"` + redactedOrEmpty(s) + `"`), nil }
+
+func (s Secret) LogValue() slog.Value { return slog.StringValue(redactedOrEmpty(s)) }
+
+// redactedOrEmpty keeps unset secrets visible as such
+func redactedOrEmpty(s Secret) string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
@@ -0,0 +1,13 @@
+package keyset
+
+import "time"
+
+// ReloadInterval is reloadInterval for the tests of package keyset_test
+const ReloadInterval = reloadInterval
+
+// SetReloadedAt pretends the directory was last read at t
+func (s *KeySet) SetReloadedAt(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reloadedAt = t
+}
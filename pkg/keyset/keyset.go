@@ -0,0 +1,354 @@
+// Package keyset manages the asymmetric keys access tokens are signed with.
+// Keys are PEM files in a directory, named <kid>.pem. Private keys can sign
+// and verify, public keys only verify, e.g. to accept tokens of another
+// deployment during a migration. The most recently created private key signs.
+package keyset
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Supported signing algorithms, as named in the JWS alg header
+const (
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+const (
+	rsaKeyBits = 2048
+	// reloadInterval limits how often an unknown kid triggers a reload of the directory
+	reloadInterval = 30 * time.Second
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrUnsupportedKey       = errors.New("unsupported key type")
+	ErrNoSigningKey         = errors.New("no signing key")
+)
+
+// Key is a key identified by the kid header of the tokens it signs
+type Key struct {
+	ID        string
+	Algorithm string
+	Public    crypto.PublicKey
+	// Private is nil for keys that only verify
+	Private   crypto.Signer
+	CreatedAt time.Time
+}
+
+// KeySet holds the keys loaded from a directory
+type KeySet struct {
+	dir       string
+	algorithm string
+
+	mu         sync.RWMutex
+	keys       map[string]*Key
+	signing    *Key
+	reloadedAt time.Time
+}
+
+// Load reads the keys in dir, creating it if needed. When no private key uses
+// algorithm a new one is generated, so changing the algorithm rotates the key.
+func Load(dir, algorithm string) (*KeySet, error) {
+	if algorithm != RS256 && algorithm != EdDSA {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	s := &KeySet{dir: dir, algorithm: algorithm}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	if signing := s.SigningKey(); signing == nil || signing.Algorithm != algorithm {
+		if _, err := s.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Reload reads the directory again, picking up keys added by operators or
+// rotated by other instances sharing it.
+func (s *KeySet) Reload() error {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*Key, len(paths))
+	var signing *Key
+	for _, path := range paths {
+		key, err := readKey(path)
+		if err != nil {
+			slog.Warn("skipping signing key", "path", path, "error", err)
+			continue
+		}
+		keys[key.ID] = key
+		if key.Private != nil && (signing == nil || key.CreatedAt.After(signing.CreatedAt)) {
+			signing = key
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+	s.signing = signing
+	s.reloadedAt = time.Now()
+	return nil
+}
+
+// SigningKey returns the key new tokens are signed with
+func (s *KeySet) SigningKey() *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.signing
+}
+
+// Key returns the key with the given ID. Unknown IDs reload the directory in
+// case another instance rotated the key.
+func (s *KeySet) Key(id string) (*Key, bool) {
+	s.mu.RLock()
+	key, ok := s.keys[id]
+	stale := time.Since(s.reloadedAt) > reloadInterval
+	s.mu.RUnlock()
+	if ok || !stale {
+		return key, ok
+	}
+
+	if err := s.Reload(); err != nil {
+		slog.Error("could not reload signing keys", "error", err)
+		return nil, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok = s.keys[id]
+	return key, ok
+}
+
+// Keys returns every loaded key
+func (s *KeySet) Keys() []*Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]*Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Rotate generates a new signing key. Previous keys keep verifying the tokens
+// they signed until pruned.
+func (s *KeySet) Rotate() (*Key, error) {
+	var private crypto.Signer
+	var err error
+	switch s.algorithm {
+	case RS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case EdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	id := now.Format("20060102T150405Z") + "-" + randomSuffix()
+	path := filepath.Join(s.dir, id+".pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return nil, err
+	}
+
+	key := &Key{ID: id, Algorithm: s.algorithm, Public: private.Public(), Private: private, CreatedAt: now}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys == nil {
+		s.keys = map[string]*Key{}
+	}
+	s.keys[id] = key
+	s.signing = key
+	slog.Info("signing key rotated", "kid", id)
+	return key, nil
+}
+
+// Prune deletes private keys that were replaced as signing key more than
+// retention ago. Retention must exceed the lifetime of the tokens they signed.
+// Public keys are left for the operator to remove.
+func (s *KeySet) Prune(retention time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, key := range s.keys {
+		if key.Private == nil || key == s.signing {
+			continue
+		}
+		// A key was retired when the next newer private key was created
+		var retiredAt time.Time
+		for _, other := range s.keys {
+			if other.Private != nil && other.CreatedAt.After(key.CreatedAt) && (retiredAt.IsZero() || other.CreatedAt.Before(retiredAt)) {
+				retiredAt = other.CreatedAt
+			}
+		}
+		if retiredAt.IsZero() || time.Since(retiredAt) < retention {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(s.dir, id+".pem")); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		delete(s.keys, id)
+		slog.Info("signing key pruned", "kid", id)
+	}
+	return nil
+}
+
+// RotateEvery rotates the signing key once it is older than interval and
+// prunes retired keys, until ctx is done.
+func (s *KeySet) RotateEvery(ctx context.Context, interval, retention time.Duration) {
+	check := interval / 10
+	if check > time.Hour {
+		check = time.Hour
+	} else if check < time.Second {
+		check = time.Second
+	}
+	ticker := time.NewTicker(check)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.Reload(); err != nil {
+			slog.Error("could not reload signing keys", "error", err)
+			continue
+		}
+		if signing := s.SigningKey(); signing == nil || time.Since(signing.CreatedAt) >= interval {
+			if _, err := s.Rotate(); err != nil {
+				slog.Error("could not rotate signing key", "error", err)
+				continue
+			}
+		}
+		if err := s.Prune(retention); err != nil {
+			slog.Error("could not prune signing keys", "error", err)
+		}
+	}
+}
+
+// JSONWebKey is the public part of a key as published in a JWKS document
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet is a JWKS document (RFC 7517)
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS returns the public keys other services need to verify tokens
+func (s *KeySet) JWKS() JSONWebKeySet {
+	keys := s.Keys()
+	jwks := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(keys))}
+	for _, key := range keys {
+		jwk := JSONWebKey{Use: "sig", Alg: key.Algorithm, Kid: key.ID}
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+// readKey parses a PKCS#8 or PKCS#1 private key, or a PKIX public key. The
+// file modification time stands in for the creation time.
+func readKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	key := &Key{ID: strings.TrimSuffix(filepath.Base(path), ".pem"), CreatedAt: info.ModTime()}
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.Private, key.Public = RS256, k, &k.PublicKey
+	case ed25519.PrivateKey:
+		key.Algorithm, key.Private, key.Public = EdDSA, k, k.Public()
+	case *rsa.PublicKey:
+		key.Algorithm, key.Public = RS256, k
+	case ed25519.PublicKey:
+		key.Algorithm, key.Public = EdDSA, k
+	default:
+		return nil, ErrUnsupportedKey
+	}
+	return key, nil
+}
+
+func randomSuffix() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("%x", b)
+}
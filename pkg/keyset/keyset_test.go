@@ -0,0 +1,214 @@
+package keyset_test
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/keyset"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// sign returns a token signed with key and carrying its kid
+func sign(t *testing.T, key *keyset.Key) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), jwt.StandardClaims{Subject: "user"})
+	token.Header["kid"] = key.ID
+	signed, err := token.SignedString(key.Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// verify checks the signature of token with the key of the set named by its kid
+func verify(set *keyset.KeySet, token string) error {
+	_, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := set.Key(kid)
+		if !ok {
+			return nil, errors.New("unknown kid")
+		}
+		return key.Public, nil
+	})
+	return err
+}
+
+// backdate sets the modification time of the key file, which stands in for
+// its creation time, to age ago
+func backdate(t *testing.T, dir string, key *keyset.Key, age time.Duration) {
+	t.Helper()
+	at := time.Now().Add(-age)
+	if err := os.Chtimes(filepath.Join(dir, key.ID+".pem"), at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// hasKeyFile reports whether the directory still holds the key
+func hasKeyFile(dir string, key *keyset.Key) bool {
+	_, err := os.Stat(filepath.Join(dir, key.ID+".pem"))
+	return err == nil
+}
+
+func TestRetiredKeyVerifiesUntilRetention(t *testing.T) {
+	dir := t.TempDir()
+	set, err := keyset.Load(dir, keyset.EdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retired := set.SigningKey()
+	token := sign(t, retired)
+
+	signing, err := set.Rotate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.SigningKey() != signing {
+		t.Fatal("the rotated key does not sign")
+	}
+	if err := verify(set, token); err != nil {
+		t.Fatalf("token of the retired key rejected after rotation: %v", err)
+	}
+
+	// Retired an hour ago, within a retention of two hours
+	backdate(t, dir, retired, 3*time.Hour)
+	backdate(t, dir, signing, time.Hour)
+	if err := set.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Prune(2 * time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := verify(set, token); err != nil || !hasKeyFile(dir, retired) {
+		t.Fatalf("retired key pruned within retention: %v", err)
+	}
+
+	// Past the retention the key is deleted and its tokens rejected
+	if err := set.Prune(30 * time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := verify(set, token); err == nil || hasKeyFile(dir, retired) {
+		t.Fatal("retired key kept past retention")
+	}
+}
+
+func TestPruneKeepsSigningKey(t *testing.T) {
+	dir := t.TempDir()
+	set, err := keyset.Load(dir, keyset.EdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signing := set.SigningKey()
+	backdate(t, dir, signing, 24*time.Hour)
+	if err := set.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := set.Prune(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if set.SigningKey() == nil || set.SigningKey().ID != signing.ID || !hasKeyFile(dir, signing) {
+		t.Fatal("Prune deleted the signing key")
+	}
+	if err := verify(set, sign(t, set.SigningKey())); err != nil {
+		t.Fatalf("token of the signing key rejected after pruning: %v", err)
+	}
+}
+
+func TestUnknownKidReloadsAtMostOncePerInterval(t *testing.T) {
+	dir := t.TempDir()
+	set, err := keyset.Load(dir, keyset.EdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Keys rotated by another instance sharing the directory
+	other, err := keyset.Load(dir, keyset.EdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := other.Rotate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := set.Key(first.ID); ok {
+		t.Fatal("unknown kid reloaded the directory right after loading it")
+	}
+
+	set.SetReloadedAt(time.Now().Add(-keyset.ReloadInterval - time.Second))
+	if _, ok := set.Key("missing"); ok {
+		t.Fatal("found a key that does not exist")
+	}
+	if _, ok := set.Key(first.ID); !ok {
+		t.Fatal("unknown kid did not reload the stale directory")
+	}
+
+	// The reload counts for the interval, whatever kid triggered it
+	second, err := other.Rotate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := set.Key(second.ID); ok {
+		t.Fatal("unknown kid reloaded the directory twice within the interval")
+	}
+}
+
+func TestParseJWTBindsAlgorithmToKey(t *testing.T) {
+	previousDir, previousAlgorithm, previousPeriod := utils.JWTKeysDir, utils.JWTSigningAlgorithm, utils.JWTKeyRotationPeriod
+	utils.JWTKeysDir, utils.JWTSigningAlgorithm, utils.JWTKeyRotationPeriod = t.TempDir(), keyset.RS256, 0
+	t.Cleanup(func() {
+		utils.JWTKeysDir, utils.JWTSigningAlgorithm, utils.JWTKeyRotationPeriod = previousDir, previousAlgorithm, previousPeriod
+	})
+	if err := utils.LoadSigningKeys(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	valid, err := utils.GenerateJWT(&utils.Claims{UserID: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := utils.ParseJWT(valid)
+	if err != nil {
+		t.Fatalf("ParseJWT rejected a valid token: %v", err)
+	}
+	kid := utils.JWKS().Keys[0].Kid
+
+	// The public key is known to everyone through the JWKS document
+	keys, err := keyset.Load(utils.JWTKeysDir, keyset.RS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := keys.Key(kid)
+	der, err := x509.MarshalPKIXPublicKey(key.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	forge := func(method jwt.SigningMethod, secret interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	for name, token := range map[string]string{
+		"HS256 with the public key": forge(jwt.SigningMethodHS256, publicPEM),
+		"HS256 with the JWT secret": forge(jwt.SigningMethodHS256, utils.JWTSecret),
+		"none":                      forge(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := utils.ParseJWT(token); !errors.Is(err, utils.ErrInvalidToken) {
+				t.Fatalf("ParseJWT() = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package logging sets up the structured logger of the application. Messages
+// logged with a request context carry the request ID, the route, the user
+// making the request and the trace, and sensitive attributes are redacted.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys whose values are never written
+var sensitiveKeys = map[string]bool{
+	"password":         true,
+	"current_password": true,
+	"new_password":     true,
+	"otp":              true,
+	"code":             true,
+	"token":            true,
+	"access_token":     true,
+	"refresh_token":    true,
+	"challenge_token":  true,
+	"secret":           true,
+	"recovery_codes":   true,
+	"authorization":    true,
+	"api_key":          true,
+}
+
+var levels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// Setup makes a logger writing to stderr the default of slog and of the log
+// package
+func Setup(settings config.LogSettings) {
+	slog.SetDefault(New(os.Stderr, settings))
+}
+
+// New returns a logger writing to w in the configured format, from the
+// configured level
+func New(w io.Writer, settings config.LogSettings) *slog.Logger {
+	options := &slog.HandlerOptions{Level: levels[settings.Level], ReplaceAttr: redact}
+	var handler slog.Handler
+	if settings.Format == "text" {
+		handler = slog.NewTextHandler(w, options)
+	} else {
+		handler = slog.NewJSONHandler(w, options)
+	}
+	return slog.New(&requestHandler{Handler: handler})
+}
+
+// redact replaces the value of sensitive attributes, at any depth
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, redacted)
+	}
+	return attr
+}
+
+// requestHandler adds the attributes of the request of the context to each
+// record
+type requestHandler struct {
+	slog.Handler
+}
+
+func (h *requestHandler) Handle(ctx context.Context, record slog.Record) error {
+	if req := FromContext(ctx); req != nil {
+		record.AddAttrs(req.attrs()...)
+	}
+	// Sampled out traces are not exported, their IDs would lead nowhere
+	if span := trace.SpanContextFromContext(ctx); span.IsSampled() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *requestHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &requestHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *requestHandler) WithGroup(name string) slog.Handler {
+	return &requestHandler{Handler: h.Handler.WithGroup(name)}
+}
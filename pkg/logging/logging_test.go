@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+)
+
+func TestRedactsSensitiveAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, config.LogSettings{Level: "info", Format: "json"})
+
+	logger.Info("payment", "otp", "123456", slog.Group("login", "Password", "hunter22", "email", "jane@example.com"))
+
+	var line struct {
+		OTP   string `json:"otp"`
+		Login struct {
+			Password string `json:"Password"`
+			Email    string `json:"email"`
+		} `json:"login"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line.OTP != redacted || line.Login.Password != redacted {
+		t.Fatalf("logged %s, want the OTP and password redacted", buf.Bytes())
+	}
+	if line.Login.Email != "jane@example.com" {
+		t.Fatalf("logged %s, want the email kept", buf.Bytes())
+	}
+}
+
+func TestAddsRequestAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, config.LogSettings{Level: "debug", Format: "json"})
+
+	req := NewRequest("req-1", "POST", func() string { return "/api/order/checkout" })
+	ctx := WithRequest(context.Background(), req)
+	logger.DebugContext(ctx, "before authentication")
+	req.SetUser("user-1")
+	logger.DebugContext(ctx, "after authentication")
+	logger.Debug("without request")
+
+	var lines []map[string]interface{}
+	for _, raw := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var line map[string]interface{}
+		if err := json.Unmarshal(raw, &line); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("%d lines logged, want 3", len(lines))
+	}
+	if lines[0]["request_id"] != "req-1" || lines[0]["route"] != "/api/order/checkout" || lines[0]["user_id"] != nil {
+		t.Fatalf("first line %v, want the request without user", lines[0])
+	}
+	if lines[1]["user_id"] != "user-1" {
+		t.Fatalf("second line %v, want the user", lines[1])
+	}
+	if _, ok := lines[2]["request_id"]; ok {
+		t.Fatalf("third line %v, want no request", lines[2])
+	}
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+type requestKey struct{}
+
+// Request identifies the HTTP request a message is logged for
+type Request struct {
+	ID     string
+	Method string
+	// route returns the route pattern matched so far, which becomes the
+	// route of the handler once routing reaches it
+	route func() string
+
+	mu     sync.Mutex
+	userID string
+}
+
+// NewRequest returns the request with the given ID, route being called when
+// a message is logged
+func NewRequest(id, method string, route func() string) *Request {
+	return &Request{ID: id, Method: method, route: route}
+}
+
+// SetUser records the authenticated user making the request
+func (r *Request) SetUser(userID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.userID = userID
+}
+
+// UserID is the authenticated user making the request, empty until set
+func (r *Request) UserID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.userID
+}
+
+func (r *Request) attrs() []slog.Attr {
+	attrs := []slog.Attr{slog.String("request_id", r.ID), slog.String("method", r.Method)}
+	if r.route != nil {
+		attrs = append(attrs, slog.String("route", r.route()))
+	}
+	if userID := r.UserID(); userID != "" {
+		attrs = append(attrs, slog.String("user_id", userID))
+	}
+	return attrs
+}
+
+// WithRequest returns a context carrying req
+func WithRequest(ctx context.Context, req *Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+// FromContext returns the request carried by ctx, nil when there is none
+func FromContext(ctx context.Context) *Request {
+	if ctx == nil {
+		return nil
+	}
+	req, _ := ctx.Value(requestKey{}).(*Request)
+	return req
+}
+
+// RequestID returns the ID of the request carried by ctx, empty when there is none
+func RequestID(ctx context.Context) string {
+	if req := FromContext(ctx); req != nil {
+		return req.ID
+	}
+	return ""
+}
@@ -0,0 +1,29 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+
+// FileSender writes every message as an .eml file, for local development
+type FileSender struct {
+	Dir  string
+	From string
+}
+
+func (s *FileSender) Send(msg Message) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("error creating mail directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000000"), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	if err := os.WriteFile(filepath.Join(s.Dir, name), format(s.From, msg), 0o644); err != nil {
+		return fmt.Errorf("error writing email: %w", err)
+	}
+	return nil
+}
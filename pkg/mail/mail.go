@@ -0,0 +1,90 @@
+package mail
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers email messages
+type Sender interface {
+	Send(msg Message) error
+}
+
+var (
+	defaultSender Sender
+	mu            sync.Mutex
+)
+
+// Default returns the sender selected by MAIL_DRIVER (smtp, file or memory)
+func Default() Sender {
+	mu.Lock()
+	defer mu.Unlock()
+	if defaultSender == nil {
+		defaultSender = newSenderFromEnv()
+	}
+	return defaultSender
+}
+
+// SetDefault replaces the sender used by Default, e.g. with a MemorySender in tests
+func SetDefault(sender Sender) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultSender = sender
+}
+
+// Send delivers a message through the default sender
+func Send(msg Message) error {
+	return Default().Send(msg)
+}
+
+func newSenderFromEnv() Sender {
+	settings := config.Get().Mail
+
+	switch strings.ToLower(settings.Driver) {
+	case "smtp":
+		return &SMTPSender{
+			Host:     settings.SMTPHost,
+			Port:     settings.SMTPPort,
+			Username: settings.SMTPUsername,
+			Password: settings.SMTPPassword.Value(),
+			From:     settings.From,
+		}
+	case "memory":
+		return NewMemorySender()
+	case "", "file":
+		dir := settings.FileDir
+		if dir == "" {
+			dir = "tmp/mail"
+		}
+		return &FileSender{Dir: dir, From: settings.From}
+	default:
+		slog.Warn("unknown MAIL_DRIVER, writing emails to tmp/mail", "driver", settings.Driver)
+		return &FileSender{Dir: "tmp/mail", From: settings.From}
+	}
+}
+
+// format renders a message as an RFC 5322 email
+func format(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,29 @@
+package mail
+
+import (
+	"fmt"
+	"net/smtp"
+)
+
+// SMTPSender delivers messages through an SMTP relay
+type SMTPSender struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (s *SMTPSender) Send(msg Message) error {
+	addr := fmt.Sprintf("%s:%d", s.Host, s.Port)
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	if err := smtp.SendMail(addr, auth, s.From, []string{msg.To}, format(s.From, msg)); err != nil {
+		return fmt.Errorf("error sending email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Package metrics holds the Prometheus metrics of the API, served in the
+// text format by Handler
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every metric of the API along with the Go runtime and
+// process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests answered, by method, route and status.",
+	}, []string{"method", "route", "status"})
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to answer HTTP requests, by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Time taken by SQL statements, by operation and table.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	// CartsCreated counts the carts created on first use by a customer
+	CartsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "store_carts_created_total",
+		Help: "Carts created.",
+	})
+	// OrdersCheckedOut counts the orders placed from a cart
+	OrdersCheckedOut = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "store_orders_checked_out_total",
+		Help: "Orders checked out.",
+	})
+	// Payments counts the payments set paid or failed by the payment gateway
+	Payments = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "store_payments_total",
+		Help: "Payments updated by the payment gateway, by status.",
+	}, []string{"status"})
+	// StockOuts counts the products found short of stock, when adding them to
+	// a cart or checking them out
+	StockOuts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "store_stock_outs_total",
+		Help: "Products found short of stock, by stage.",
+	}, []string{"stage"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpRequestDuration, dbQueryDuration,
+		CartsCreated, OrdersCheckedOut, Payments, StockOuts,
+	)
+	// Business series exist from the start, so rates are right from the
+	// first event
+	for _, status := range []string{"paid", "failed"} {
+		Payments.WithLabelValues(status)
+	}
+	for _, stage := range []string{"cart", "checkout"} {
+		StockOuts.WithLabelValues(stage)
+	}
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveRequest records an HTTP request answered with status after duration.
+// route is the pattern of the route, not the path, to bound the number of series.
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpRequestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveQuery records an SQL statement of operation on table run in duration
+func ObserveQuery(operation, table string, duration time.Duration) {
+	dbQueryDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
+}
+
+var (
+	poolMu        sync.Mutex
+	poolCollector prometheus.Collector
+)
+
+// WatchPool reports the connection pool stats of db, in place of the pool
+// watched before
+func WatchPool(db *sql.DB, name string) {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if poolCollector != nil {
+		Registry.Unregister(poolCollector)
+	}
+	poolCollector = collectors.NewDBStatsCollector(db, name)
+	Registry.MustRegister(poolCollector)
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyMiddleware authenticates requests carrying an X-API-Key header. Requests
+// without the header are passed on unchanged, so it is placed in front of
+// JWTMiddleware to accept either. Scopes are checked by RequirePermission.
+func APIKeyMiddleware(c *fiber.Ctx) error {
+	key := c.Get(APIKeyHeader)
+	if key == "" {
+		return c.Next()
+	}
+
+	apiKey, err := service.AuthenticateAPIKey(key, c.IP())
+	if err != nil {
+		response := dto.NewErrorResponse("Unauthorized", err.Error())
+		return c.Status(fiber.StatusUnauthorized).JSON(response)
+	}
+
+	c.Locals("apiKey", apiKey)
+	return c.Next()
+}
+
+// authenticatedByAPIKey reports whether APIKeyMiddleware already authenticated the request
+func authenticatedByAPIKey(c *fiber.Ctx) bool {
+	_, ok := c.Locals("apiKey").(*models.APIKey)
+	return ok
+}
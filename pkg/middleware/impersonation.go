@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AllowWhileImpersonating lets impersonation tokens call a write endpoint that
+// only affects the token itself, such as logout. It must run before JWTMiddleware.
+func AllowWhileImpersonating(c *fiber.Ctx) error {
+	c.Locals("allowImpersonation", true)
+	return c.Next()
+}
+
+// deniedWhileImpersonating are the paths impersonation tokens cannot read,
+// with their subpaths: the data export and the sessions of the customer are
+// theirs alone, they are not needed to reproduce what the customer sees.
+var deniedWhileImpersonating = []string{"/api/user/export", "/api/user/sessions"}
+
+// impersonated runs the rest of the chain for an impersonation token. Write
+// requests and denied paths are refused and every request is recorded in the
+// audit trail.
+func impersonated(c *fiber.Ctx, claims *utils.Claims, userID uuid.UUID) error {
+	actorID, err := uuid.Parse(claims.Actor.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.NewErrorResponse("Unauthorized", nil))
+	}
+
+	entry := models.ImpersonationLog{
+		ActorRefer:   actorID,
+		SubjectRefer: userID,
+		TokenID:      claims.Id,
+		Method:       c.Method(),
+		Path:         c.OriginalURL(),
+		IP:           c.IP(),
+	}
+
+	allowed, _ := c.Locals("allowImpersonation").(bool)
+	if !allowed && !isReadOnly(c.Method()) {
+		entry.Status = fiber.StatusForbidden
+		entry.Blocked = true
+		recordImpersonatedRequest(c, entry)
+		return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse("Forbidden", "write operations are not allowed while impersonating"))
+	}
+	if isDeniedWhileImpersonating(c.Path()) {
+		entry.Status = fiber.StatusForbidden
+		entry.Blocked = true
+		recordImpersonatedRequest(c, entry)
+		return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse("Forbidden", "this endpoint is not available while impersonating"))
+	}
+
+	err = c.Next()
+	entry.Status = c.Response().StatusCode()
+	if fiberErr, ok := err.(*fiber.Error); ok {
+		entry.Status = fiberErr.Code
+	}
+	recordImpersonatedRequest(c, entry)
+	return err
+}
+
+func recordImpersonatedRequest(c *fiber.Ctx, entry models.ImpersonationLog) {
+	if err := service.RecordImpersonatedRequest(entry); err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to record impersonated request", "actor_id", entry.ActorRefer, "error", err)
+	}
+}
+
+// isDeniedWhileImpersonating matches path the way the router does, ignoring
+// case and a trailing slash
+func isDeniedWhileImpersonating(path string) bool {
+	path = strings.TrimSuffix(strings.ToLower(path), "/")
+	for _, denied := range deniedWhileImpersonating {
+		if path == denied || strings.HasPrefix(path, denied+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func isReadOnly(method string) bool {
+	return method == fiber.MethodGet || method == fiber.MethodHead || method == fiber.MethodOptions
+}
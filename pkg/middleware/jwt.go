@@ -1,18 +1,22 @@
 package middleware
 
 import (
-	"log"
 	"strings"
 
 	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/logging"
 	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
 // JWTMiddleware validates the JWT and extracts the user ID
 func JWTMiddleware(c *fiber.Ctx) error {
+	if authenticatedByAPIKey(c) {
+		return c.Next()
+	}
+
 	// Get the token from the Authorization header
 	authHeader := c.Get("Authorization")
 
@@ -30,37 +34,43 @@ func JWTMiddleware(c *fiber.Ctx) error {
 	tokenString := parts[1]
 
 	// Parse the JWT token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return utils.GetJWTSecret(), nil
-	})
-	if err != nil || !token.Valid {
+	claims, err := utils.ParseJWT(tokenString)
+	if err != nil {
 		response := dto.NewErrorResponse("Unauthorized", nil)
 		return c.Status(fiber.StatusUnauthorized).JSON(response)
 	}
 
-	// Extract user ID from the token
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		response := dto.NewErrorResponse("Unauthorized", nil)
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		response := dto.NewErrorResponse("Unauthorized", err)
 		return c.Status(fiber.StatusUnauthorized).JSON(response)
 	}
 
-	userID, err := uuid.Parse(claims["user_id"].(string))
-	if err != nil {
-		response := dto.NewErrorResponse("Unauthorized", err)
+	if claims.Role == "" {
+		response := dto.NewErrorResponse("Unauthorized", nil)
 		return c.Status(fiber.StatusUnauthorized).JSON(response)
 	}
 
-	role, ok := claims["role"].(string)
-	if !ok {
-		response := dto.NewErrorResponse("Unauthorized", err)
+	// Reject tokens revoked by logout, password change or token reuse, and suspended users
+	if err := service.ValidateAccessToken(claims); err != nil {
+		if err == service.ErrAccountSuspended {
+			return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse("Forbidden", err.Error()))
+		}
+		response := dto.NewErrorResponse("Unauthorized", err.Error())
 		return c.Status(fiber.StatusUnauthorized).JSON(response)
 	}
 
-	log.Printf("user role: %s", role)
 	// Store the user ID in the context
+	if req := logging.FromContext(c.UserContext()); req != nil {
+		req.SetUser(userID.String())
+	}
 	c.Locals("userID", userID)
-	c.Locals("role", role)
+	c.Locals("role", claims.Role)
+	c.Locals("claims", claims)
+
+	if claims.Actor != nil {
+		return impersonated(c, claims, userID)
+	}
 
 	// Proceed to the next handler
 	return c.Next()
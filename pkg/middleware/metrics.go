@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/metrics"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// unmatchedRoute labels the requests matching no route, such as scans of
+// random paths, so they share one series
+const unmatchedRoute = "unmatched"
+
+// Metrics records the count and duration of requests by route
+func Metrics(c *fiber.Ctx) error {
+	start := time.Now()
+	err := c.Next()
+
+	status := responseStatus(c, err)
+	route := c.Route().Path
+	// Handlers answer missing records themselves, a returned 404 comes from
+	// the router finding no route
+	if err != nil && status == fiber.StatusNotFound {
+		route = unmatchedRoute
+	}
+	// The method is only valid during the request, labels outlive it
+	metrics.ObserveRequest(utils.CopyString(c.Method()), route, status, time.Since(start))
+	return err
+}
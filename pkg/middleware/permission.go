@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/models"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission allows the request when the role of the user (set in JWT
+// middleware) or the scopes of the API key (set in API key middleware) grant permission
+func RequirePermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if apiKey, ok := c.Locals("apiKey").(*models.APIKey); ok {
+			if !apiKey.HasPermission(permission) {
+				response := dto.NewErrorResponse("Forbidden", service.ErrAPIKeyForbidden.Error())
+				return c.Status(fiber.StatusForbidden).JSON(response)
+			}
+			return c.Next()
+		}
+
+		role, _ := c.Locals("role").(string)
+		if !models.RoleHasPermission(role, permission) {
+			response := dto.NewErrorResponse("Forbidden", "missing permission "+permission)
+			return c.Status(fiber.StatusForbidden).JSON(response)
+		}
+
+		return c.Next()
+	}
+}
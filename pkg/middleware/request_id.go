@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/logging"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID of a request, kept when the client or a
+// proxy sets it and generated otherwise
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern keeps client IDs that cannot forge log lines
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID gives every request an ID, returned in the X-Request-ID header and
+// in error responses. The user context of the request carries it, so messages
+// logged with it, including SQL statements, can be matched to the request.
+func RequestID(c *fiber.Ctx) error {
+	id := c.Get(RequestIDHeader)
+	if !requestIDPattern.MatchString(id) {
+		id = uuid.NewString()
+	}
+	c.Set(RequestIDHeader, id)
+
+	req := logging.NewRequest(id, c.Method(), func() string { return c.Route().Path })
+	c.SetUserContext(logging.WithRequest(c.UserContext(), req))
+
+	// Errors returned are answered by ErrorHandler, which adds the ID itself
+	err := c.Next()
+	if err == nil {
+		addRequestID(c, id)
+	}
+	return err
+}
+
+// RequestLogger logs every request once answered
+func RequestLogger(c *fiber.Ctx) error {
+	start := time.Now()
+	err := c.Next()
+
+	status := responseStatus(c, err)
+	level := slog.LevelInfo
+	if status >= fiber.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	// The query string is left out as it may hold secrets, e.g. payment OTPs
+	slog.LogAttrs(c.UserContext(), level, "request",
+		slog.String("path", c.Path()),
+		slog.Int("status", status),
+		slog.Duration("duration", time.Since(start)),
+		slog.String("ip", c.IP()),
+		slog.Int("bytes", len(c.Response().Body())))
+	return err
+}
+
+// ErrorHandler answers the errors returned by handlers, such as fiber.ErrNotFound
+// for unknown routes, with an error response
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	status, message := fiber.StatusInternalServerError, "Internal Server Error"
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		status, message = fiberErr.Code, fiberErr.Message
+	}
+	if status >= fiber.StatusInternalServerError {
+		slog.ErrorContext(c.UserContext(), "request failed", "error", err)
+	}
+
+	if err := c.Status(status).JSON(dto.NewErrorResponse(message, nil)); err != nil {
+		return err
+	}
+	addRequestID(c, logging.RequestID(c.UserContext()))
+	return nil
+}
+
+// responseStatus is the status of the response to a request, or the one
+// ErrorHandler answers err with
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+// addRequestID adds the request_id field to JSON error responses
+func addRequestID(c *fiber.Ctx, id string) {
+	resp := c.Response()
+	body := resp.Body()
+	if id == "" || resp.StatusCode() < fiber.StatusBadRequest || len(body) < 2 || body[0] != '{' ||
+		!bytes.HasPrefix(resp.Header.ContentType(), []byte(fiber.MIMEApplicationJSON)) {
+		return
+	}
+
+	field, _ := json.Marshal(id)
+	patched := append([]byte(`{"request_id":`), field...)
+	if rest := bytes.TrimSpace(body[1:]); len(rest) > 0 && rest[0] != '}' {
+		patched = append(patched, ',')
+	}
+	resp.SetBody(append(patched, body[1:]...))
+}
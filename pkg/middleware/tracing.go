@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/logging"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/tracing"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier gives the propagator access to the request headers
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string { return h.c.Get(key) }
+
+func (h headerCarrier) Set(key, value string) { h.c.Request().Header.Set(key, value) }
+
+func (h headerCarrier) Keys() []string {
+	var keys []string
+	h.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+// Tracing starts the span of each request, continuing the trace of the
+// traceparent header when there is one. Services and SQL statements run with
+// the user context of the request add their spans below it.
+func Tracing(c *fiber.Ctx) error {
+	ctx := tracing.Propagator.Extract(c.UserContext(), headerCarrier{c})
+	method := utils.CopyString(c.Method())
+	ctx, span := tracing.Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.URLPath(utils.CopyString(c.Path())),
+			semconv.ClientAddress(c.IP()),
+		))
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	err := c.Next()
+
+	status := responseStatus(c, err)
+	route := c.Route().Path
+	if err != nil && status == fiber.StatusNotFound {
+		route = unmatchedRoute
+	} else {
+		span.SetAttributes(semconv.HTTPRoute(route))
+	}
+	span.SetName(method + " " + route)
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if id := logging.RequestID(c.UserContext()); id != "" {
+		span.SetAttributes(attribute.String("request.id", id))
+	}
+	if status >= fiber.StatusInternalServerError {
+		span.SetStatus(codes.Error, "")
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/dto"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TwoFactorMiddleware rejects tokens obtained without a second factor when the
+// 2FA policy applies to the user's role (set in JWT middleware)
+func TwoFactorMiddleware(c *fiber.Ctx) error {
+	if authenticatedByAPIKey(c) {
+		return c.Next()
+	}
+
+	claims, ok := c.Locals("claims").(*utils.Claims)
+	if !ok {
+		response := dto.NewErrorResponse("Unauthorized", nil)
+		return c.Status(fiber.StatusUnauthorized).JSON(response)
+	}
+
+	if service.IsTwoFactorRequired(claims.Role) && !claims.MFA {
+		response := dto.NewErrorResponse("Two-factor authentication required", "Enable two-factor authentication at /api/user/2fa/setup and log in again")
+		return c.Status(fiber.StatusForbidden).JSON(response)
+	}
+
+	return c.Next()
+}
@@ -0,0 +1,112 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// useProviders makes Lookup discover the providers of configs until the end
+// of the test, starting without any provider known
+func useProviders(t *testing.T, configs ...Config) {
+	previousProviders, previousFailures, previousConfig := providers, failures, providerConfig
+	providers, failures = map[string]*Provider{}, map[string]discoveryFailure{}
+	providerConfig = func(name string) (Config, bool) {
+		for _, cfg := range configs {
+			if cfg.Name == name {
+				return cfg, true
+			}
+		}
+		return Config{}, false
+	}
+	t.Cleanup(func() { providers, failures, providerConfig = previousProviders, previousFailures, previousConfig })
+}
+
+// failingIssuer answers every discovery request with an error once release is
+// closed, counting the requests
+func failingIssuer(t *testing.T, release chan struct{}) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestLookupSharesOneDiscovery(t *testing.T) {
+	release := make(chan struct{})
+	server, requests := failingIssuer(t, release)
+	useProviders(t, Config{Name: "slow", IssuerURL: server.URL})
+	Register(&Provider{Config: Config{Name: "ready"}})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := Lookup(context.Background(), "slow")
+			errs <- err
+		}()
+	}
+
+	// Other providers stay available while one is being discovered
+	for requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := Lookup(context.Background(), "ready"); err != nil {
+		t.Fatalf("Lookup(ready) = %v while discovering another provider", err)
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err == nil {
+			t.Fatal("Lookup succeeded against a failing issuer")
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("%d discovery requests for concurrent lookups, want 1", got)
+	}
+}
+
+func TestLookupBacksOffAfterFailure(t *testing.T) {
+	release := make(chan struct{})
+	close(release)
+	server, requests := failingIssuer(t, release)
+	useProviders(t, Config{Name: "down", IssuerURL: server.URL})
+
+	first, err := Lookup(context.Background(), "down")
+	if err == nil || first != nil {
+		t.Fatalf("Lookup() = %v, %v, want a discovery error", first, err)
+	}
+	if _, again := Lookup(context.Background(), "down"); again != err {
+		t.Fatalf("Lookup() = %v within the retry interval, want the cached %v", again, err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("%d discovery requests within the retry interval, want 1", got)
+	}
+
+	// Once the interval is over the provider is asked again
+	failures["down"] = discoveryFailure{err: err, at: time.Now().Add(-discoveryRetryInterval)}
+	if _, err := Lookup(context.Background(), "down"); err == nil {
+		t.Fatal("Lookup succeeded against a failing issuer")
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("%d discovery requests after the retry interval, want 2", got)
+	}
+}
+
+func TestLookupUnknownProvider(t *testing.T) {
+	useProviders(t)
+	if _, err := Lookup(context.Background(), "missing"); err != ErrUnknownProvider {
+		t.Fatalf("Lookup() = %v, want ErrUnknownProvider", err)
+	}
+}
@@ -0,0 +1,232 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	ErrMissingIDToken  = errors.New("token response has no id_token")
+	ErrInvalidNonce    = errors.New("id_token nonce does not match")
+)
+
+// discoveryTimeout bounds the request fetching the provider's discovery document
+const discoveryTimeout = 10 * time.Second
+
+// Config describes an OpenID Connect identity provider registered with the API
+type Config struct {
+	// Name identifies the provider in URLs and linked identities, e.g. "google"
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// TrustEmail allows linking to an existing account with the same verified email
+	TrustEmail bool
+}
+
+// Identity is the authenticated end-user as asserted by the provider's ID token
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider runs the authorization code flow with PKCE against one identity provider
+type Provider struct {
+	Config
+	oauth2   oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+// NewProvider fetches the discovery document of the issuer and prepares the client.
+func NewProvider(ctx context.Context, cfg Config) (*Provider, error) {
+	ctx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+
+	provider, err := gooidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("oidc discovery for %s: %w", cfg.Name, err)
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{gooidc.ScopeOpenID, "email", "profile"}
+	}
+
+	return &Provider{
+		Config: cfg,
+		oauth2: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier: provider.Verifier(&gooidc.Config{ClientID: cfg.ClientID}),
+	}, nil
+}
+
+// AuthCodeURL returns the URL the user is sent to for signing in. The code
+// verifier and nonce must be kept until the callback.
+func (p *Provider) AuthCodeURL(state, nonce, codeVerifier string) string {
+	return p.oauth2.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(codeVerifier))
+}
+
+// Exchange redeems the authorization code and verifies the returned ID token.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, ErrMissingIDToken
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	if idToken.Nonce != nonce {
+		return nil, ErrInvalidNonce
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	return &Identity{
+		Issuer:        idToken.Issuer,
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// GenerateVerifier returns a random PKCE code verifier
+func GenerateVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+// discoveryRetryInterval is how long a failed discovery is reported to every
+// lookup before the provider is asked again
+const discoveryRetryInterval = 30 * time.Second
+
+// discoveryFailure is the last failed discovery of a provider
+type discoveryFailure struct {
+	err error
+	at  time.Time
+}
+
+var (
+	providers = map[string]*Provider{}
+	failures  = map[string]discoveryFailure{}
+	mu        sync.Mutex
+	// discoveries runs a single discovery per provider for concurrent lookups
+	discoveries singleflight.Group
+	// providerConfig reads the settings of a provider, replaced in tests
+	providerConfig = configFromEnv
+)
+
+// Lookup returns the provider registered under name. Providers listed in
+// OIDC_PROVIDERS are discovered on first use; a failed discovery is returned
+// again for discoveryRetryInterval instead of reaching the provider on every
+// request.
+func Lookup(ctx context.Context, name string) (*Provider, error) {
+	mu.Lock()
+	provider, ok := providers[name]
+	failure, failed := failures[name]
+	mu.Unlock()
+	if ok {
+		return provider, nil
+	}
+	if failed && time.Since(failure.at) < discoveryRetryInterval {
+		return nil, failure.err
+	}
+
+	cfg, ok := providerConfig(name)
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	// The discovery runs without holding mu, so a slow provider does not block
+	// lookups of the others, and is not canceled when the first caller gives up
+	result, err, _ := discoveries.Do(name, func() (interface{}, error) {
+		provider, err := NewProvider(context.WithoutCancel(ctx), cfg)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			failures[name] = discoveryFailure{err: err, at: time.Now()}
+			return nil, err
+		}
+		delete(failures, name)
+		// Keep a provider registered while discovering
+		if registered, ok := providers[name]; ok {
+			return registered, nil
+		}
+		providers[name] = provider
+		return provider, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Provider), nil
+}
+
+// Register adds or replaces a provider, e.g. one backed by oidctest.Server in tests
+func Register(provider *Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[provider.Name] = provider
+	delete(failures, provider.Name)
+}
+
+// configFromEnv reads OIDC_<NAME>_* settings for a provider listed in OIDC_PROVIDERS
+func configFromEnv(name string) (Config, bool) {
+	enabled := false
+	for _, candidate := range config.Get().Auth.OIDCProviders {
+		if candidate == name && name != "" {
+			enabled = true
+		}
+	}
+	if !enabled {
+		return Config{}, false
+	}
+
+	prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+	redirectURL := config.Config(prefix + "REDIRECT_URL")
+	if redirectURL == "" {
+		redirectURL = strings.TrimRight(config.Get().Server.BaseURL, "/") + "/api/auth/oidc/" + name + "/callback"
+	}
+
+	return Config{
+		Name:         name,
+		IssuerURL:    config.Config(prefix + "ISSUER_URL"),
+		ClientID:     config.Config(prefix + "CLIENT_ID"),
+		ClientSecret: config.Config(prefix + "CLIENT_SECRET"),
+		RedirectURL:  redirectURL,
+		Scopes:       strings.Fields(config.Config(prefix + "SCOPES")),
+		TrustEmail:   config.ConfigBool(prefix+"TRUST_EMAIL", false),
+	}, true
+}
@@ -0,0 +1,111 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/oidc"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/oidc/oidctest"
+)
+
+const redirectURL = "http://localhost:8080/api/auth/oidc/test/callback"
+
+func newProvider(t *testing.T) (*oidc.Provider, *oidctest.Server) {
+	t.Helper()
+	server := oidctest.NewServer("client-id", "client-secret")
+	t.Cleanup(server.Close)
+
+	provider, err := oidc.NewProvider(context.Background(), oidc.Config{
+		Name:         "test",
+		IssuerURL:    server.Issuer(),
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURL:  redirectURL,
+	})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	return provider, server
+}
+
+// authorize follows the authorization URL and returns the code and state sent to the redirect URL
+func authorize(t *testing.T, authURL string) (string, string) {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize: status %d", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	provider, server := newProvider(t)
+	server.SetUser(oidctest.User{Subject: "42", Email: "jane@corp.example", EmailVerified: true, Name: "Jane"})
+
+	verifier := oidc.GenerateVerifier()
+	code, state := authorize(t, provider.AuthCodeURL("state-1", "nonce-1", verifier))
+	if state != "state-1" {
+		t.Fatalf("state = %q, want state-1", state)
+	}
+
+	identity, err := provider.Exchange(context.Background(), code, verifier, "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := oidc.Identity{Issuer: server.Issuer(), Subject: "42", Email: "jane@corp.example", EmailVerified: true, Name: "Jane"}
+	if *identity != want {
+		t.Fatalf("identity = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	provider, _ := newProvider(t)
+
+	code, _ := authorize(t, provider.AuthCodeURL("state", "nonce", oidc.GenerateVerifier()))
+	if _, err := provider.Exchange(context.Background(), code, oidc.GenerateVerifier(), "nonce"); err == nil {
+		t.Fatal("Exchange succeeded with a wrong code verifier")
+	}
+}
+
+func TestExchangeRejectsWrongNonce(t *testing.T) {
+	provider, _ := newProvider(t)
+
+	verifier := oidc.GenerateVerifier()
+	code, _ := authorize(t, provider.AuthCodeURL("state", "nonce", verifier))
+	if _, err := provider.Exchange(context.Background(), code, verifier, "other-nonce"); err != oidc.ErrInvalidNonce {
+		t.Fatalf("Exchange error = %v, want %v", err, oidc.ErrInvalidNonce)
+	}
+}
+
+func TestCodeIsSingleUse(t *testing.T) {
+	provider, _ := newProvider(t)
+
+	verifier := oidc.GenerateVerifier()
+	code, _ := authorize(t, provider.AuthCodeURL("state", "nonce", verifier))
+	if _, err := provider.Exchange(context.Background(), code, verifier, "nonce"); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if _, err := provider.Exchange(context.Background(), code, verifier, "nonce"); err == nil {
+		t.Fatal("second Exchange with the same code succeeded")
+	}
+}
+
+func TestLookupUnknownProvider(t *testing.T) {
+	if _, err := oidc.Lookup(context.Background(), "not-configured"); err != oidc.ErrUnknownProvider {
+		t.Fatalf("Lookup error = %v, want %v", err, oidc.ErrUnknownProvider)
+	}
+}
@@ -0,0 +1,96 @@
+package passhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id hashes passwords with argon2id. Hashes use the PHC string format,
+// $argon2id$v=19$m=<memory KiB>,t=<iterations>,p=<parallelism>$<salt>$<key>
+type Argon2id struct {
+	// Memory in KiB
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+type argon2idHash struct {
+	params Argon2id
+	salt   []byte
+	key    []byte
+}
+
+func (a Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, a.Iterations, a.Memory, a.Parallelism, a.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, a.Memory, a.Iterations, a.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a Argon2id) Verify(encoded, password string) (bool, error) {
+	hash, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	p := hash.params
+	key := argon2.IDKey([]byte(password), hash.salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return subtle.ConstantTimeCompare(key, hash.key) == 1, nil
+}
+
+func (a Argon2id) Identifies(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (a Argon2id) Outdated(encoded string) bool {
+	hash, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	p := hash.params
+	return p.Memory != a.Memory || p.Iterations != a.Iterations || p.Parallelism != a.Parallelism ||
+		p.SaltLength != a.SaltLength || p.KeyLength != a.KeyLength
+}
+
+func decodeArgon2id(encoded string) (*argon2idHash, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, ErrUnknownHash
+	}
+
+	var hash argon2idHash
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &hash.params.Memory, &hash.params.Iterations, &hash.params.Parallelism); err != nil {
+		return nil, ErrUnknownHash
+	}
+
+	var err error
+	if hash.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, ErrUnknownHash
+	}
+	if hash.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, ErrUnknownHash
+	}
+	hash.params.SaltLength = uint32(len(hash.salt))
+	hash.params.KeyLength = uint32(len(hash.key))
+	if hash.params.Iterations == 0 || hash.params.Parallelism == 0 || hash.params.KeyLength == 0 {
+		return nil, ErrUnknownHash
+	}
+	return &hash, nil
+}
@@ -0,0 +1,83 @@
+// Package passhash hashes and verifies user passwords. Hashes are encoded
+// with their algorithm and parameters, so hashes produced with older settings
+// keep verifying and can be detected for an upgrade.
+package passhash
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+)
+
+// Supported algorithms
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// Hasher is a password hashing algorithm with its parameters
+type Hasher interface {
+	// Hash returns the encoded hash of password, including the algorithm parameters
+	Hash(password string) (string, error)
+	// Verify reports whether password matches an encoded hash of this algorithm
+	Verify(encoded, password string) (bool, error)
+	// Identifies reports whether encoded was produced by this algorithm
+	Identifies(encoded string) bool
+	// Outdated reports whether encoded was produced with other parameters than the hasher's
+	Outdated(encoded string) bool
+}
+
+var (
+	// Current hashes new passwords
+	Current Hasher
+	// hashers verify existing hashes, whatever algorithm is current
+	hashers []Hasher
+)
+
+func init() {
+	settings := config.Get().Password
+	bcryptHasher := Bcrypt{Cost: settings.BcryptCost}
+	argon2idHasher := Argon2id{
+		Memory:      settings.Argon2Memory,
+		Iterations:  settings.Argon2Iterations,
+		Parallelism: settings.Argon2Parallelism,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+	hashers = []Hasher{bcryptHasher, argon2idHasher}
+
+	switch algorithm := settings.Algorithm; algorithm {
+	case AlgorithmBcrypt:
+		Current = bcryptHasher
+	case AlgorithmArgon2id, "":
+		Current = argon2idHasher
+	default:
+		slog.Warn("unknown PASSWORD_HASH_ALGORITHM, using "+AlgorithmArgon2id, "algorithm", algorithm)
+		Current = argon2idHasher
+	}
+}
+
+// Hash hashes password with the current algorithm
+func Hash(password string) (string, error) {
+	return Current.Hash(password)
+}
+
+// Verify checks password against an encoded hash of any supported algorithm.
+// rehash is set when the hash matches but should be replaced by Hash(password)
+// because the algorithm or its parameters changed.
+func Verify(encoded, password string) (match bool, rehash bool, err error) {
+	for _, hasher := range hashers {
+		if !hasher.Identifies(encoded) {
+			continue
+		}
+		match, err = hasher.Verify(encoded, password)
+		if err != nil || !match {
+			return false, false, err
+		}
+		return true, !Current.Identifies(encoded) || Current.Outdated(encoded), nil
+	}
+	return false, false, ErrUnknownHash
+}
@@ -0,0 +1,112 @@
+package passhash
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	testBcrypt   = Bcrypt{Cost: bcrypt.MinCost}
+	testArgon2id = Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+)
+
+// useHashers makes current hash new passwords and the test hashers verify
+// existing ones until the end of the test
+func useHashers(t *testing.T, current Hasher) {
+	previousCurrent, previousHashers := Current, hashers
+	Current, hashers = current, []Hasher{testBcrypt, testArgon2id}
+	t.Cleanup(func() { Current, hashers = previousCurrent, previousHashers })
+}
+
+// mustHash hashes password with hasher, failing the test on error
+func mustHash(t *testing.T, hasher Hasher, password string) string {
+	t.Helper()
+	encoded, err := hasher.Hash(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
+
+func TestVerify(t *testing.T) {
+	stronger := testArgon2id
+	stronger.Iterations = 2
+
+	tests := []struct {
+		name       string
+		current    Hasher
+		hashedWith Hasher
+		password   string
+		wantMatch  bool
+		wantRehash bool
+	}{
+		{name: "bcrypt", current: testBcrypt, hashedWith: testBcrypt, password: "secret-password", wantMatch: true},
+		{name: "bcrypt mismatch", current: testBcrypt, hashedWith: testBcrypt, password: "other-password"},
+		{name: "argon2id", current: testArgon2id, hashedWith: testArgon2id, password: "secret-password", wantMatch: true},
+		{name: "argon2id mismatch", current: testArgon2id, hashedWith: testArgon2id, password: "other-password"},
+		{name: "bcrypt when argon2id is current", current: testArgon2id, hashedWith: testBcrypt, password: "secret-password", wantMatch: true, wantRehash: true},
+		{name: "argon2id when bcrypt is current", current: testBcrypt, hashedWith: testArgon2id, password: "secret-password", wantMatch: true, wantRehash: true},
+		{name: "bcrypt of another cost", current: Bcrypt{Cost: bcrypt.MinCost + 1}, hashedWith: testBcrypt, password: "secret-password", wantMatch: true, wantRehash: true},
+		{name: "argon2id of other parameters", current: stronger, hashedWith: testArgon2id, password: "secret-password", wantMatch: true, wantRehash: true},
+		{name: "no rehash on mismatch", current: testArgon2id, hashedWith: testBcrypt, password: "other-password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useHashers(t, tt.current)
+			encoded := mustHash(t, tt.hashedWith, "secret-password")
+
+			match, rehash, err := Verify(encoded, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if match != tt.wantMatch || rehash != tt.wantRehash {
+				t.Fatalf("Verify() = match %v, rehash %v, want %v, %v", match, rehash, tt.wantMatch, tt.wantRehash)
+			}
+		})
+	}
+}
+
+func TestVerifyUnknownHashes(t *testing.T) {
+	useHashers(t, testArgon2id)
+	valid := mustHash(t, testArgon2id, "secret-password")
+	parts := strings.Split(valid, "$")
+
+	for name, encoded := range map[string]string{
+		"empty":              "",
+		"plain text":         "secret-password",
+		"other algorithm":    "$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"other version":      strings.Replace(valid, "$v=19$", "$v=16$", 1),
+		"missing parameters": strings.Replace(valid, "$"+parts[3]+"$", "$m=1024$", 1),
+		"zero iterations":    strings.Replace(valid, ",t=1,", ",t=0,", 1),
+		"invalid salt":       strings.Replace(valid, "$"+parts[4]+"$", "$not base64!$", 1),
+		"missing key":        strings.Join(parts[:5], "$"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			match, rehash, err := Verify(encoded, "secret-password")
+			if !errors.Is(err, ErrUnknownHash) || match || rehash {
+				t.Fatalf("Verify(%q) = %v, %v, %v, want ErrUnknownHash", encoded, match, rehash, err)
+			}
+		})
+	}
+}
+
+func TestArgon2idHash(t *testing.T) {
+	first := mustHash(t, testArgon2id, "secret-password")
+	second := mustHash(t, testArgon2id, "secret-password")
+
+	if !strings.HasPrefix(first, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("hash %q, want the PHC format with the parameters", first)
+	}
+	if first == second {
+		t.Fatal("two hashes of the same password are equal, want a random salt")
+	}
+	if !testArgon2id.Identifies(first) || testBcrypt.Identifies(first) {
+		t.Fatalf("hash %q identified by the wrong algorithm", first)
+	}
+	if testArgon2id.Outdated(first) {
+		t.Fatalf("hash %q outdated with the parameters it was made with", first)
+	}
+}
@@ -0,0 +1,87 @@
+// Package tracing sets up OpenTelemetry tracing and starts the spans of the
+// API. Trace context is read from and written to W3C traceparent, tracestate
+// and baggage headers.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of the spans started here
+const instrumentationName = "github.com/arsyaputraa/go-synapsis-challenge"
+
+// Propagator reads and writes the W3C trace context and baggage headers. It
+// applies whatever the exporter, so trace IDs reach the logs even when spans
+// are not exported.
+var Propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Setup installs the tracer provider exporting spans as configured and
+// returns the function flushing and stopping it on shutdown
+func Setup(ctx context.Context, settings config.TracingSettings) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(Propagator)
+	if settings.Exporter == "none" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch settings.Exporter {
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		var options []otlptracehttp.Option
+		if settings.OTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(settings.OTLPEndpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create the %s span exporter: %w", settings.Exporter, err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithAttributes(semconv.ServiceName(settings.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("could not describe the service: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(settings.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start starts a span named name as a child of the span in ctx. The tracer is
+// looked up on each call, so a provider installed later, e.g. by a test,
+// records it.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.GetTracerProvider().Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// Fail marks span as failed with err, when err is not nil
+func Fail(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -1,25 +1,201 @@
 package utils
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
-	"github.com/dgrijalva/jwt-go"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/keyset"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 )
 
-var jwtSecret = []byte(config.Config("JWT_SECRET_KEY"))
+// JWTSecret signs tokens only this API reads: action links and OIDC state.
+// Access tokens are signed with signingKeys so other services can verify them.
+var JWTSecret = []byte(config.Get().JWT.SecretKey.Value())
 
-func GenerateJWT(userID uuid.UUID, role string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID.String(),
-		"role":    role,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
+var (
+	AccessTokenTTL  = config.Get().JWT.AccessTokenTTL
+	RefreshTokenTTL = config.Get().JWT.RefreshTokenTTL
+)
+
+var (
+	JWTKeysDir           = config.Get().JWT.KeysDir
+	JWTSigningAlgorithm  = config.Get().JWT.SigningAlgorithm
+	JWTKeyRotationPeriod = config.Get().JWT.KeyRotationInterval
+	// JWTKeyRetention must exceed AccessTokenTTL, retired keys are deleted afterwards
+	JWTKeyRetention = config.Get().JWT.KeyRetention
+)
+
+var signingKeys *keyset.KeySet
+
+// LoadSigningKeys loads the access token signing keys and, unless
+// JWT_KEY_ROTATION_INTERVAL is 0, rotates them in the background until ctx is done.
+func LoadSigningKeys(ctx context.Context) error {
+	keys, err := keyset.Load(JWTKeysDir, JWTSigningAlgorithm)
+	if err != nil {
+		return err
+	}
+	signingKeys = keys
+	if JWTKeyRotationPeriod > 0 {
+		go keys.RotateEvery(ctx, JWTKeyRotationPeriod, JWTKeyRetention)
+	}
+	return nil
+}
+
+// JWKS returns the public keys access tokens can be verified with
+func JWKS() keyset.JSONWebKeySet {
+	if signingKeys == nil {
+		return keyset.JSONWebKeySet{Keys: []keyset.JSONWebKey{}}
+	}
+	return signingKeys.JWKS()
+}
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are the claims carried by an access token
+type Claims struct {
+	UserID       string `json:"user_id"`
+	Role         string `json:"role"`
+	TokenVersion int    `json:"ver"`
+	// MFA is set when the user completed a second factor to obtain the token
+	MFA bool `json:"mfa,omitempty"`
+	// SessionID identifies the login session the token belongs to
+	SessionID string `json:"sid"`
+	// Actor is set on impersonation tokens, UserID is then the impersonated user
+	Actor *ActorClaims `json:"act,omitempty"`
+	jwt.StandardClaims
+}
+
+// ActorClaims identify who is acting on behalf of the subject (RFC 8693)
+type ActorClaims struct {
+	UserID string `json:"sub"`
+}
+
+// GenerateJWT signs a short-lived access token for the given claims. A unique
+// token ID (jti) is assigned so the token can be revoked before it expires.
+func GenerateJWT(claims *Claims) (string, error) {
+	return GenerateJWTWithTTL(claims, AccessTokenTTL)
+}
+
+// GenerateJWTWithTTL is GenerateJWT with a lifetime other than AccessTokenTTL
+func GenerateJWTWithTTL(claims *Claims, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.Id = uuid.New().String()
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(ttl).Unix()
+
+	if signingKeys == nil || signingKeys.SigningKey() == nil {
+		return "", keyset.ErrNoSigningKey
+	}
+	key := signingKeys.SigningKey()
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// ParseJWT validates the signature and expiry of an access token and returns its claims
+func ParseJWT(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if signingKeys == nil || kid == "" {
+			return nil, ErrInvalidToken
+		}
+		key, ok := signingKeys.Key(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %s", kid)
+		}
+		// The algorithm is bound to the key, never taken from the token alone
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.Public, nil
 	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	if claims.Id == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// ActionClaims are the claims carried by single-purpose tokens such as email
+// verification links. They can never be used as access tokens.
+type ActionClaims struct {
+	Purpose string `json:"purpose"`
+	Email   string `json:"email,omitempty"`
+	jwt.StandardClaims
+}
+
+// GenerateActionToken signs a token that is only accepted for the given purpose
+func GenerateActionToken(purpose string, userID uuid.UUID, email string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &ActionClaims{
+		Purpose: purpose,
+		Email:   email,
+		StandardClaims: jwt.StandardClaims{
+			Id:        uuid.NewString(),
+			Subject:   userID.String(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ttl).Unix(),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(JWTSecret)
+}
 
-	return token.SignedString(jwtSecret)
+// ParseActionToken validates a token generated by GenerateActionToken for the given purpose
+func ParseActionToken(tokenString string, purpose string) (*ActionClaims, error) {
+	claims := &ActionClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return GetJWTSecret(), nil
+	})
+	if err != nil || !token.Valid || claims.Purpose != purpose {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
 }
 
 func GetJWTSecret() []byte {
-	return jwtSecret
+	return JWTSecret
+}
+
+// OIDCStateClaims keep the state, nonce and PKCE verifier of an OpenID Connect
+// login between the redirect to the identity provider and the callback
+type OIDCStateClaims struct {
+	Provider string `json:"provider"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	jwt.StandardClaims
+}
+
+// GenerateOIDCState signs the claims of an OpenID Connect login. The token ID
+// is used as the state parameter sent to the provider.
+func GenerateOIDCState(claims *OIDCStateClaims, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(ttl).Unix()
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(JWTSecret)
+}
+
+// ParseOIDCState validates a token generated by GenerateOIDCState
+func ParseOIDCState(tokenString string) (*OIDCStateClaims, error) {
+	claims := &OIDCStateClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return GetJWTSecret(), nil
+	})
+	if err != nil || !token.Valid || claims.Id == "" || claims.Provider == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
 }
@@ -23,7 +23,9 @@ func (p *paginate) paginatedResult(db *gorm.DB) *gorm.DB {
       Limit(p.limit)
 }
 
-func GetPaginatedQuery[T interface{}](model T, pageInput string, limitInput string) (*gorm.DB, int,int) {
+// ParsePagination reads the page and limit query values, page 1 and 10 items
+// when missing or invalid
+func ParsePagination(pageInput string, limitInput string) (int, int) {
 	page, err := strconv.Atoi(pageInput)
     if err != nil || page < 1 {
         page = 1
@@ -32,5 +34,10 @@ func GetPaginatedQuery[T interface{}](model T, pageInput string, limitInput stri
     if err != nil || limit < 1 {
         limit = 10
     }
+	return page, limit
+}
+
+func GetPaginatedQuery[T interface{}](model T, pageInput string, limitInput string) (*gorm.DB, int,int) {
+	page, limit := ParsePagination(pageInput, limitInput)
 	return database.Database.Db.Model(model).Scopes(newPaginate(limit, page).paginatedResult), page, limit
 }
\ No newline at end of file
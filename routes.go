@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+// runRoutes prints the method and path of every route
+func runRoutes(args []string) error {
+	if err := parseFlags(flag.NewFlagSet("routes", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	routes := newApp().GetRoutes(true)
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH")
+	for _, route := range routes {
+		// Fiber registers a HEAD route for every GET route
+		if route.Method == http.MethodHead {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\n", route.Method, route.Path)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+)
+
+// runSeed creates or updates the records of a fixture file
+func runSeed(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	file := flags.String("file", "", "JSON or YAML fixture file")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errUsage
+	}
+
+	result, err := database.SeedFile(*file)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d records created, %d updated\n", result.Created, result.Updated)
+	return nil
+}
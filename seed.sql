@@ -1,2 +0,0 @@
--- Enable the uuid-ossp extension
-CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
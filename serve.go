@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/database"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/delivery/http/router"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/repository"
+	"github.com/arsyaputraa/go-synapsis-challenge/internal/service"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/middleware"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/tracing"
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/swagger"
+)
+
+// runServe prepares the schema and signing keys, then serves the API until
+// SIGTERM or SIGINT
+func runServe(args []string) error {
+	if err := parseFlags(flag.NewFlagSet("serve", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+	slog.Info("configuration loaded", "settings", config.Get().String())
+
+	if err := database.PrepareSchema(); err != nil {
+		return err
+	}
+	if settings := config.Get(); !settings.Production() && settings.Database.SeedFile != "" {
+		result, err := database.SeedFile(settings.Database.SeedFile)
+		if err != nil {
+			return fmt.Errorf("could not seed %s: %w", settings.Database.SeedFile, err)
+		}
+		slog.Info("seeded fixtures", "file", settings.Database.SeedFile, "created", result.Created, "updated", result.Updated)
+	}
+	if err := utils.LoadSigningKeys(context.Background()); err != nil {
+		return fmt.Errorf("could not load JWT signing keys: %w", err)
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), config.Get().Tracing)
+	if err != nil {
+		return err
+	}
+	// Spans still buffered are exported before exiting
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("could not flush traces", "error", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Once draining, a second signal stops the process right away
+	context.AfterFunc(ctx, stop)
+	err = serve(ctx, newApp(), config.Get().Server)
+
+	// Emails requested by the last requests are sent before exiting
+	waitCtx, cancel := context.WithTimeout(context.Background(), config.Get().Server.ShutdownTimeout)
+	defer cancel()
+	if waitErr := service.WaitBackground(waitCtx); waitErr != nil {
+		slog.Error("background work still running at exit", "error", waitErr)
+	}
+	return err
+}
+
+// serve answers requests until ctx is done, then stops accepting connections
+// and waits for the requests in flight, up to the shutdown timeout
+func serve(ctx context.Context, app *fiber.App, settings config.ServerSettings) error {
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(settings.Address)
+	}()
+
+	select {
+	case err := <-listenErr:
+		return err
+	case <-ctx.Done():
+	}
+	slog.Info("shutting down, draining requests in flight", "timeout", settings.ShutdownTimeout)
+	if err := app.ShutdownWithTimeout(settings.ShutdownTimeout); err != nil {
+		return fmt.Errorf("requests still in flight after %s: %w", settings.ShutdownTimeout, err)
+	}
+	if err := <-listenErr; err != nil {
+		return err
+	}
+	slog.Info("server stopped")
+	return nil
+}
+
+// newApp returns the Fiber app with every middleware and route
+func newApp() *fiber.App {
+	settings := config.Get().Server
+	app := fiber.New(fiber.Config{
+		// Behind a reverse proxy the client IP used for login throttling comes from this header
+		ProxyHeader: settings.ProxyHeader,
+		// Only proxies are trusted with it, other clients get their own IP
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          settings.TrustedProxies,
+		ReadTimeout:             settings.ReadTimeout,
+		WriteTimeout:            settings.WriteTimeout,
+		IdleTimeout:             settings.IdleTimeout,
+		ErrorHandler:            middleware.ErrorHandler,
+	})
+	app.Use(middleware.Tracing, middleware.RequestID, middleware.Metrics)
+	if settings.RequestLog {
+		app.Use(middleware.RequestLogger)
+	}
+	app.Use(cors.New(cors.Config{AllowOrigins: strings.Join(settings.CORSOrigins, ",")}))
+	app.Get("/swagger/*", swagger.HandlerDefault)
+	router.SetupRoutes(app, router.NewHandlers(repository.NewStore(database.Database.Db), config.Get().Auth))
+	return app
+}
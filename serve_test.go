@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/arsyaputraa/go-synapsis-challenge/pkg/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+// slowApp answers GET /slow after delay, closing started when a request begins
+func slowApp(delay time.Duration, started chan<- struct{}) *fiber.App {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		close(started)
+		time.Sleep(delay)
+		return c.SendString("done")
+	})
+	return app
+}
+
+// startServe runs serve in the background until the returned cancel is
+// called, and waits for it to accept connections
+func startServe(t *testing.T, app *fiber.App, timeout time.Duration) (string, context.CancelFunc, <-chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := ln.Addr().String()
+	ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, app, config.ServerSettings{Address: address, ShutdownTimeout: timeout})
+	}()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if conn, err := net.Dial("tcp", address); err == nil {
+			conn.Close()
+			return address, cancel, served
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server not listening on %s", address)
+		}
+	}
+}
+
+func TestServeDrainsRequestsInFlight(t *testing.T) {
+	started := make(chan struct{})
+	address, shutdown, served := startServe(t, slowApp(300*time.Millisecond, started), 5*time.Second)
+
+	answered := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + address + "/slow")
+		if err != nil {
+			answered <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		answered <- string(body)
+	}()
+	<-started
+	shutdown()
+
+	if body := <-answered; body != "done" {
+		t.Fatalf("request in flight answered %q, want done", body)
+	}
+	if err := <-served; err != nil {
+		t.Fatalf("serve returned %v, want nil once drained", err)
+	}
+	if conn, err := net.Dial("tcp", address); err == nil {
+		conn.Close()
+		t.Fatal("connection accepted after shutdown")
+	}
+}
+
+func TestServeGivesUpDrainingAfterTheTimeout(t *testing.T) {
+	started := make(chan struct{})
+	address, shutdown, served := startServe(t, slowApp(2*time.Second, started), 100*time.Millisecond)
+
+	go http.Get("http://" + address + "/slow")
+	<-started
+	begin := time.Now()
+	shutdown()
+
+	if err := <-served; err == nil {
+		t.Fatal("serve returned nil with a request still in flight")
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Fatalf("shutdown took %s, want about the 100ms timeout", elapsed)
+	}
+}